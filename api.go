@@ -13,20 +13,18 @@
 package cos
 
 import (
-	"errors"
+	"sync/atomic"
 	"time"
 )
 
 var (
-	// ErrNotExists 文件不存在。
-	ErrNotExists = errors.New("file not found")
-	// PartSize 分片上传下载时，每个分片的大小。不可在文件上传下载期间修改值。
+	// PartSize 分片上传下载时，每个分片的大小。仅作为 NewClient 的默认值，可用 WithPartSize 单独设置。
 	PartSize = 10 * 1024 * 1024
-	// MultiThreshold 文件大小超过多少个分片大小后，启用分片模式传输。
+	// MultiThreshold 文件大小超过多少个分片大小后，启用分片模式传输。仅作为 NewClient 的默认值，可用 WithMultiThreshold 单独设置。
 	MultiThreshold = 10
-	// NumRoutines 分片上传下载时，并发运行协程的数量。
+	// NumRoutines 分片上传下载时，并发运行协程的数量。仅作为 NewClient 的默认值，可用 WithNumRoutines 单独设置。
 	NumRoutines = 5
-	// AuthExpirationTime 每一个 HTTP 请求的凭证时效。
+	// AuthExpirationTime 每一个 HTTP 请求的凭证时效。仅作为 NewClient 的默认值，可用 WithAuthExpirationTime 单独设置。
 	AuthExpirationTime = 10 * time.Minute
 )
 
@@ -36,15 +34,22 @@ type Api interface {
 	Downloader
 	Deleter
 	Querier
+	Restorer
+	Copier
+	Mover
+	FormUploader
 }
 
-// NewClient 创建 COS Object 操作客户端。
+// NewBucketClient 使用存储桶名称、APPID 和地域创建客户端，访问存储桶的默认域名 <bucket>-<appId>.cos.<region>.myqcloud.com。
+// 可使用 WithAccelerate、WithCustomDomain、WithDownloadDomain 切换域名。
+func NewBucketClient(bucket, appId, region, appKey, secretKey string, opts ...option) Api {
+	return NewClient(BucketHost(bucket, appId, region), appKey, secretKey, opts...)
+}
+
+// NewClient 创建 COS Object 操作客户端。appKey、secretKey 都为空且没有使用 WithCredentialProvider 时匿名访问，
+// 请求不携带签名，用于公有读的存储桶。
 func NewClient(host, appKey, secretKey string, opts ...option) Api {
-	c := &baseImpl{
-		appKey:    appKey,
-		secretKey: secretKey,
-		host:      host,
-	}
+	c := &baseImpl{host: host, clockOffset: &atomic.Int64{}}
 
 	// 设置参数。
 	for _, v := range opts {
@@ -54,11 +59,27 @@ func NewClient(host, appKey, secretKey string, opts ...option) Api {
 		v(&c.options)
 	}
 
+	// 未设置的参数使用默认值。
+	c.suit(&options{
+		partSize:           int64(PartSize),
+		multiThreshold:     MultiThreshold,
+		numRoutines:        NumRoutines,
+		authExpirationTime: AuthExpirationTime,
+	})
+	c.bytesPool = newBytesPool(c.partSize)
+	if c.credentials == nil && (len(appKey) > 0 || len(secretKey) > 0) { // 没有密钥时匿名访问，请求不签名。
+		c.credentials = newCredentialCache(NewStaticCredentialProvider(appKey, secretKey, ""))
+	}
+
 	multiUploader := &multiUploadImpl{c}
 	uploader := &uploadImpl{c, multiUploader}
 	downloader := &downloadImpl{c, multiUploader}
 	querier := &queryImpl{c}
 	deleter := &deleteImpl{c}
+	restorer := &restoreImpl{c}
+	copier := &copyImpl{c, multiUploader}
+	mover := &moveImpl{copier}
+	formUploader := &postImpl{c}
 
-	return &impl{c, uploader, downloader, deleter, querier}
+	return &impl{c, uploader, downloader, deleter, querier, restorer, copier, mover, formUploader}
 }
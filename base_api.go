@@ -24,5 +24,11 @@ type Baser interface {
 	Ping(ctx context.Context) error
 
 	// GenerateAuthorization 生成 HTTP 请求的签名字符串。
+	// 使用临时密钥且 header 不为 nil 时，会在 header 中设置 x-cos-security-token，发送请求时须携带。
 	GenerateAuthorization(fileId, method string, query url.Values, header http.Header, expiration time.Duration) string
+
+	// PresignUrl 生成带签名的请求链接，在 expiration 时间内有效，可交给浏览器、移动端等直接访问 COS。
+	// query 是要签名的请求参数，如 response-content-disposition、uploadId、partNumber，会附加在链接中。
+	// header 是要签名的 HTTP 头，使用链接时必须携带相同的头。使用临时密钥时，链接中会带上 x-cos-security-token。
+	PresignUrl(method, fileId string, query url.Values, header http.Header, expiration time.Duration) string
 }
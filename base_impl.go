@@ -22,15 +22,22 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+	"unsafe"
 )
 
 type baseImpl struct {
-	host, appKey, secretKey string
+	host string
 	options
+	bytesPool *sync.Pool
+	// 服务端时间与本地时间的偏差，同一客户端的所有调用共享。
+	clockOffset *atomic.Int64
 }
 
 // Ping 测试连接。
@@ -46,7 +53,17 @@ func (c *baseImpl) Ping(ctx context.Context) error {
 func (c *baseImpl) GenerateAuthorization(fileId, method string, query url.Values, header http.Header,
 	expiration time.Duration) string {
 
-	fileId = suitFileId(fileId)
+	credential := c.credential()
+	if len(credential.SessionToken) > 0 && header != nil {
+		header.Set(securityTokenHeader, credential.SessionToken)
+	}
+	return c.authorization(credential, suitFileId(fileId), method, query, header, expiration)
+}
+
+// 使用密钥生成签名字符串。
+func (c *baseImpl) authorization(credential Credential, fileId, method string, query url.Values,
+	header http.Header, expiration time.Duration) string {
+
 	if query == nil {
 		query = url.Values{}
 	}
@@ -55,11 +72,7 @@ func (c *baseImpl) GenerateAuthorization(fileId, method string, query url.Values
 	}
 
 	// 生成签名有效时间 KeyTime。
-	var keyTime string
-	{
-		now := time.Now()
-		keyTime = fmt.Sprintf("%d;%d", now.Unix(), now.Add(expiration).Unix())
-	}
+	keyTime, _ := c.keyTime(expiration)
 
 	// 生成 UrlParamList 和 HttpParameters。
 	var httpParameters string
@@ -120,12 +133,7 @@ func (c *baseImpl) GenerateAuthorization(fileId, method string, query url.Values
 	}
 
 	// 生成 API 密钥 SignKey。
-	var signKey string
-	{
-		hash := hmac.New(sha1.New, []byte(c.secretKey))
-		hash.Write([]byte(keyTime))
-		signKey = fmt.Sprintf("%x", hash.Sum(nil))
-	}
+	signKey := hmacSha1(credential.SecretKey, keyTime)
 
 	// 生成过程参数 HttpString。
 	var httpString string
@@ -143,23 +151,142 @@ func (c *baseImpl) GenerateAuthorization(fileId, method string, query url.Values
 	}
 
 	// 生成过程参数 Signature。
-	var signature string
-	{
-		hash := hmac.New(sha1.New, []byte(signKey))
-		hash.Write([]byte(stringToSign))
-		signature = fmt.Sprintf("%x", hash.Sum(nil))
-	}
+	signature := hmacSha1(signKey, stringToSign)
 
 	// 生成签名。
 	return fmt.Sprintf(
 		"q-sign-algorithm=sha1&q-ak=%s&q-sign-time=%s&q-key-time=%s&q-header-list=%s&q-url-param-list=%s&q-signature=%s",
-		c.appKey, keyTime, keyTime, headerList, urlParamList, signature)
+		credential.SecretId, keyTime, keyTime, headerList, urlParamList, signature)
+}
+
+// 获取密钥，用于无法返回错误的签名方法。获取失败或匿名访问时返回空密钥。
+func (c *baseImpl) credential() Credential {
+	if c.credentials == nil {
+		return Credential{}
+	}
+	credential, err := c.credentials.get(context.Background())
+	c.logError(context.Background(), "retrieve credential failed", err)
+	return credential
+}
+
+// 获取密钥并签名请求，使用临时密钥时带上令牌。每次发送前重新签名，重试时密钥和签名时间都是最新的。
+func (c *baseImpl) sign(ctx context.Context, req *http.Request) error {
+	req.Header.Del("Authorization")
+	if c.credentials == nil { // 匿名访问。
+		req.Header.Del(securityTokenHeader)
+		return nil
+	}
+	credential, err := c.credentials.get(ctx)
+	if err != nil {
+		return err
+	}
+	if len(credential.SessionToken) > 0 {
+		req.Header.Set(securityTokenHeader, credential.SessionToken)
+	} else {
+		req.Header.Del(securityTokenHeader)
+	}
+	fileId := strings.TrimPrefix(req.URL.Path, "/")
+	req.Header.Set("Authorization",
+		c.authorization(credential, fileId, req.Method, req.URL.Query(), req.Header, c.authExpirationTime))
+	return nil
+}
+
+// 生成签名有效时间，格式为 开始时间戳;结束时间戳。end 是结束时间。
+func (c *baseImpl) keyTime(expiration time.Duration) (keyTime string, end time.Time) {
+	now := c.now()
+	end = now.Add(expiration)
+	return fmt.Sprintf("%d;%d", now.Unix(), end.Unix()), end
+}
 
+// 校正后的当前时间，用于签名。
+func (c *baseImpl) now() time.Time {
+	return time.Now().Add(time.Duration(c.clockOffset.Load()))
 }
 
-// 发送 HTTP 请求。
-func (c *baseImpl) sendHttp(ctx context.Context, req *http.Request) (rsp *http.Response, err error) {
+// 请求因本地时间偏差过大失败时，根据响应的 Date 头校正时间偏差。返回是否校正了。
+func (c *baseImpl) correctClock(err error) bool {
+	var e *Error
+	if !errors.As(err, &e) || !errors.Is(e, ErrRequestTimeTooSkewed) || e.serverTime.IsZero() {
+		return false
+	}
+	c.clockOffset.Store(int64(time.Until(e.serverTime)))
+	return true
+}
+
+// 计算 HMAC-SHA1，返回十六进制字符串。
+func hmacSha1(key, data string) string {
+	hash := hmac.New(sha1.New, []byte(key))
+	hash.Write([]byte(data))
+	return fmt.Sprintf("%x", hash.Sum(nil))
+}
+
+// PresignUrl 生成带签名的请求链接。
+func (c *baseImpl) PresignUrl(method, fileId string, query url.Values, header http.Header,
+	expiration time.Duration) string {
+
+	fileId = suitFileId(fileId)
+	signed := make(url.Values, len(query)+2)
+	for k, v := range query {
+		signed[k] = slices.Clone(v)
+	}
+	credential := c.credential()
+	if len(credential.SessionToken) > 0 {
+		signed.Set(securityTokenHeader, credential.SessionToken)
+	}
+	objectPath := c.objectPath(fileId)
+	signString := c.authorization(credential, objectPath[1:], method, signed, header, expiration)
+	signed.Set("sign", signString)
+
+	u := &url.URL{Scheme: "http", Host: c.requestHost(), Path: objectPath, RawQuery: signed.Encode()}
+	if c.tls {
+		u.Scheme = "https"
+	}
+	return u.String()
+}
+
+// 发送 HTTP 请求，每一次请求都经过拦截器。失败时按重试策略重试，本地时间偏差过大时校正后重试一次。
+func (c *baseImpl) sendHttp(ctx context.Context, operation string, req *http.Request) (rsp *http.Response,
+	err error) {
+
 	defer rollbackRequest(req) // 回收请求体。
+	if err = c.encryption.check(req); err != nil {
+		return nil, err
+	}
+	skewCorrected := false
+	key := c.objectKey(req)
+	for attempt, retries := 1, 0; ; attempt, retries = attempt+1, retries+1 {
+		if err = c.sign(ctx, req); err != nil {
+			return nil, err
+		}
+		op := &Operation{Name: operation, Key: key, Request: req.Clone(ctx)} // 请求对象会被回收，拦截器使用副本。
+		start := time.Now()
+		rsp, err = c.invoke(ctx, op)
+		c.logRequest(ctx, op, rsp, err, start, retries)
+		if err == nil {
+			return rsp, nil
+		}
+
+		// 本地时间偏差过大时，校正后立即重试一次，不计入重试次数。否则等待后重试。
+		if c.correctClock(err) && !skewCorrected && req.GetBody != nil {
+			skewCorrected = true
+			attempt--
+		} else if req.GetBody == nil || !c.retryPolicy.shouldRetry(ctx, attempt, err) {
+			return rsp, err
+		} else if e := sleepWithContext(ctx, c.retryPolicy.delay(attempt, err)); e != nil {
+			return nil, e
+		}
+
+		// 重放请求体。
+		body, e := req.GetBody()
+		if e != nil {
+			return nil, err
+		}
+		req.Body = body
+	}
+}
+
+// 发送一次 HTTP 请求。
+func (c *baseImpl) doHttp(ctx context.Context, req *http.Request) (rsp *http.Response, err error) {
 	req = req.WithContext(ctx)
 	if c.client == nil {
 		rsp, err = http.DefaultClient.Do(req)
@@ -175,12 +302,7 @@ func (c *baseImpl) sendHttp(ctx context.Context, req *http.Request) (rsp *http.R
 
 	// 非成功的响应码就返回错误。
 	if !(rsp.StatusCode >= 200 && rsp.StatusCode < 300) {
-		if rsp.StatusCode == http.StatusNotFound {
-			closeRsp(rsp)
-			return nil, ErrNotExists
-		}
-		return nil, fmt.Errorf("status codeis %d, method is %v, reqPath is %v, rspBody is %s",
-			rsp.StatusCode, req.Method, req.URL.Path, string(readAndClose(rsp)))
+		return nil, newError(req.Method, c.objectKey(req), rsp, c.readAndClose(rsp))
 	}
 
 	return rsp, nil
@@ -195,18 +317,18 @@ func (c *baseImpl) genReq(method, fileId string, query url.Values, header http.H
 	if header == nil {
 		header = http.Header{}
 	}
-	header.Set("Host", c.host)
+	host := c.requestHost()
+	header.Set("Host", host)
 	if len(content) > 0 {
 		header.Set("Content-Length", strconv.Itoa(len(content)))
 	}
-	header.Set("Authorization", c.GenerateAuthorization(fileId, method, query, header, AuthExpirationTime))
 
 	// 生成 URL。
 	schema := "http"
 	if c.tls {
 		schema = "https"
 	}
-	u, _ := url.Parse(fmt.Sprintf("%s://%s/%s?%s", schema, c.host, strings.TrimLeft(fileId, "/"), query.Encode()))
+	u, _ := url.Parse(fmt.Sprintf("%s://%s%s?%s", schema, host, c.objectPath(fileId), query.Encode()))
 
 	// 获取响应体，并赋值。
 	req := getRequest()
@@ -214,8 +336,9 @@ func (c *baseImpl) genReq(method, fileId string, query url.Values, header http.H
 	req.URL = u
 	req.Header = header
 	req.Body = io.NopCloser(bytes.NewReader(content))
+	req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(content)), nil }
 	req.ContentLength = int64(len(content))
-	req.Host = c.host
+	req.Host = host
 
 	return req
 }
@@ -231,18 +354,18 @@ func (c *baseImpl) genReqForReader(method, fileId string, query url.Values, head
 	if header == nil {
 		header = http.Header{}
 	}
-	header.Set("Host", c.host)
+	host := c.requestHost()
+	header.Set("Host", host)
 	if contentLength > 0 {
 		header.Set("Content-Length", strconv.FormatInt(contentLength, 10))
 	}
-	header.Set("Authorization", c.GenerateAuthorization(fileId, method, query, header, AuthExpirationTime))
 
 	// 生成 URL。
 	schema := "http"
 	if c.tls {
 		schema = "https"
 	}
-	u, _ := url.Parse(fmt.Sprintf("%s://%s/%s?%s", schema, c.host, strings.TrimLeft(fileId, "/"), query.Encode()))
+	u, _ := url.Parse(fmt.Sprintf("%s://%s%s?%s", schema, host, c.objectPath(fileId), query.Encode()))
 
 	// 获取请求体，并赋值。
 	req := getRequest()
@@ -251,29 +374,125 @@ func (c *baseImpl) genReqForReader(method, fileId string, query url.Values, head
 	req.Header = header
 	req.Body = io.NopCloser(content)
 	req.ContentLength = contentLength
-	req.Host = c.host
+
+	// 可寻址的读取流，重试时可以回到起始位置重放。
+	if seeker, ok := content.(io.Seeker); ok {
+		if start, err := seeker.Seek(0, io.SeekCurrent); err == nil {
+			req.GetBody = func() (io.ReadCloser, error) {
+				if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+					return nil, err
+				}
+				return io.NopCloser(content), nil
+			}
+		}
+	}
+	req.Host = host
 
 	return req
 }
 
 // 发送 HTTP/HEAD 请求。
 func (c *baseImpl) head(ctx context.Context, fileId string) (*http.Response, error) {
-	req := c.genReq(http.MethodHead, fileId, nil, nil, nil)
-	rsp, err := c.sendHttp(ctx, req)
-	closeRsp(rsp)
+	header := c.mergeConditionHeader(c.mergeEncryptionHeader(nil))
+	req := c.genReq(http.MethodHead, fileId, c.versionQuery(nil), header, nil)
+	rsp, err := c.sendHttp(ctx, OperationHeadObject, req)
+	c.closeRsp(rsp)
 	return rsp, err
 }
 
-// 获取文件大小。
-func (c *baseImpl) getFileSize(ctx context.Context, fileId string) (int64, error) {
+// 获取文件大小和服务端的 CRC64。
+func (c *baseImpl) getFileSizeAndCrc64(ctx context.Context, fileId string) (int64, string, error) {
 	rsp, err := c.head(ctx, fileId)
 	if err != nil {
-		return 0, err
+		return 0, "", err
 	}
 	length := rsp.ContentLength
 	if length <= 0 {
 		lengthStr := rsp.Header.Get("Content-Length")
 		length, _ = strconv.ParseInt(lengthStr, 10, 64)
 	}
-	return length, nil
+	return length, rsp.Header.Get("x-cos-hash-crc64ecma"), nil
+}
+
+// 设置了版本 ID 时，在请求参数中加上 versionId。
+func (c *baseImpl) versionQuery(query url.Values) url.Values {
+	if len(c.versionId) <= 0 {
+		return query
+	}
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("versionId", c.versionId)
+	return query
+}
+
+// 使用调用参数覆盖客户端参数，返回新的客户端。没有参数时返回自身。
+func (c *baseImpl) with(opts []option) *baseImpl {
+	if len(opts) <= 0 {
+		return c
+	}
+	nc := *c
+	for _, v := range opts {
+		if v != nil {
+			v(&nc.options)
+		}
+	}
+	nc.suit(&c.options)
+	if nc.partSize != c.partSize {
+		nc.bytesPool = newBytesPool(nc.partSize)
+	}
+	return &nc
+}
+
+// 将上传时的 HTTP 头和加密的 HTTP 头合并到 header 中。
+func (c *baseImpl) mergeUploadHeader(header http.Header) http.Header {
+	if header == nil {
+		header = http.Header{}
+	}
+	for k, v := range c.uploadHeader {
+		header[k] = slices.Clone(v)
+	}
+	c.encryption.setHeader(header, true)
+	return header
+}
+
+// 将读取文件、上传分片时需要的加密 HTTP 头合并到 header 中。
+func (c *baseImpl) mergeEncryptionHeader(header http.Header) http.Header {
+	if header == nil {
+		header = http.Header{}
+	}
+	c.encryption.setHeader(header, false)
+	return header
+}
+
+// 将条件请求的 HTTP 头合并到 header 中。
+func (c *baseImpl) mergeConditionHeader(header http.Header) http.Header {
+	if header == nil {
+		header = http.Header{}
+	}
+	for k, v := range c.conditionHeader {
+		header[k] = slices.Clone(v)
+	}
+	return header
+}
+
+// 获取字节数组。
+func (c *baseImpl) makeBytes() []byte {
+	return c.bytesPool.Get().([]byte)
+}
+
+// 回收字节数组。
+func (c *baseImpl) rollbackBytes(data []byte) {
+	if int64(cap(data)) != c.partSize {
+		return
+	}
+	if cap(data) > len(data) {
+		data = unsafe.Slice(&data[0], cap(data))
+	}
+	c.bytesPool.Put(data)
+}
+
+// 判断文件大小是否用分片模式传输。
+func (c *baseImpl) useMultipart(size int64) bool {
+	return size > int64(c.multiThreshold)*c.partSize || size > 5*1024*1024*1024 // 大于 5GiB，必须要用分片上传。
 }
@@ -26,6 +26,7 @@ import (
 	"strings"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	cos "gitee.com/ivfzhou/tencent-cos-object-api"
 )
@@ -385,3 +386,136 @@ func CheckAuthorization(auth, path, method string, wantHeader http.Header, wantQ
 	}
 	return signature == sign
 }
+
+func TestPresignUrl(t *testing.T) {
+	client := cos.NewClient(host, appKey, appSecret, cos.WithHttps())
+	check := func(rawUrl, method, path string, header http.Header, query url.Values) {
+		u, err := url.Parse(rawUrl)
+		if err != nil {
+			t.Fatalf("unexpected error: want nil, got %v", err)
+		}
+		if u.Scheme != "https" || u.Host != host || u.Path != path {
+			t.Errorf("unexpected url: got %v", rawUrl)
+		}
+		gotQuery := u.Query()
+		sign := gotQuery.Get("sign")
+		gotQuery.Del("sign")
+		if gotQuery.Encode() != query.Encode() {
+			t.Errorf("unexpected query: want %v, got %v", query, gotQuery)
+		}
+		if !CheckAuthorization(sign, path, method, header, query) {
+			t.Errorf("unexpected sign: got %v", sign)
+		}
+	}
+
+	t.Run("自定义签名", func(t *testing.T) {
+		query := url.Values{}
+		query.Set("response-content-disposition", "attachment; filename=a b.txt")
+		header := http.Header{}
+		header.Set("Content-Type", "text/plain")
+		rawUrl := client.PresignUrl(http.MethodGet, "/dir/a b.txt", query, header, cos.AuthExpirationTime)
+		check(rawUrl, http.MethodGet, "/dir/a b.txt", header, query)
+		if !strings.Contains(rawUrl, "/dir/a%20b.txt?") {
+			t.Errorf("unexpected url: got %v", rawUrl)
+		}
+	})
+
+	t.Run("下载链接", func(t *testing.T) {
+		rawUrl := client.GetDownloadUrl("dir/a.txt", cos.AuthExpirationTime)
+		check(rawUrl, http.MethodGet, "/dir/a.txt", nil, url.Values{})
+	})
+
+	t.Run("上传链接", func(t *testing.T) {
+		rawUrl, header := client.GetUploadUrl("dir/a.txt", cos.AuthExpirationTime,
+			cos.WithContentType("text/plain"), cos.WithMetadata(map[string]string{"owner": "ivfzhou"}))
+		if header.Get("Content-Type") != "text/plain" || header.Get("x-cos-meta-owner") != "ivfzhou" {
+			t.Errorf("unexpected header: got %v", header)
+		}
+		check(rawUrl, http.MethodPut, "/dir/a.txt", header, url.Values{})
+	})
+
+	t.Run("分片上传链接", func(t *testing.T) {
+		rawUrl := client.GetUploadPartUrl("dir/a.txt", "upload id", 3, cos.AuthExpirationTime)
+		query := url.Values{}
+		query.Set("uploadId", "upload id")
+		query.Set("partNumber", "3")
+		check(rawUrl, http.MethodPut, "/dir/a.txt", nil, query)
+	})
+}
+
+func TestClockSkew(t *testing.T) {
+	// 服务端时间比本地快一小时。
+	serverTime := func() time.Time { return time.Now().Add(time.Hour) }
+	signTime := func(auth string) int64 {
+		for _, v := range strings.Split(auth, "&") {
+			if begin, ok := strings.CutPrefix(v, "q-sign-time="); ok {
+				begin, _, _ = strings.Cut(begin, ";")
+				n, _ := strconv.ParseInt(begin, 10, 64)
+				return n
+			}
+		}
+		return 0
+	}
+	skewed := func(auth string) bool {
+		diff := signTime(auth) - serverTime().Unix()
+		return diff > 60 || diff < -60
+	}
+	newHandler := func(count *int32, withDate bool) func(*http.Request) (*http.Response, error) {
+		return func(req *http.Request) (*http.Response, error) {
+			atomic.AddInt32(count, 1)
+			auth := req.Header.Get("Authorization")
+			if !CheckAuthorization(auth, req.URL.Path, req.Method, req.Header, req.URL.Query()) {
+				t.Errorf("unexpected auth: got %v", auth)
+			}
+			if !skewed(auth) {
+				return &http.Response{StatusCode: http.StatusOK, Body: NewReader(nil, nil, nil, nil)}, nil
+			}
+			header := http.Header{}
+			if withDate {
+				header.Set("Date", serverTime().UTC().Format(http.TimeFormat))
+			}
+			return &http.Response{StatusCode: http.StatusForbidden, Header: header, Body: NewReader([]byte(
+				`<Error><Code>RequestTimeTooSkewed</Code></Error>`), nil, nil, nil)}, nil
+		}
+	}
+
+	t.Run("校正时间", func(t *testing.T) {
+		var count int32
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(newHandler(&count, true))))
+		ctx := context.Background()
+		if err := client.Ping(ctx); err != nil {
+			t.Errorf("unexpected error: want nil, got %v", err)
+		}
+		if count != 2 {
+			t.Errorf("unexpected request count: want 2, got %v", count)
+		}
+
+		// 之后的请求、签名链接、表单都使用校正后的时间。
+		if err := client.Upload(ctx, "a.txt", []byte("a"), cos.WithNonCheckCrc64()); err != nil {
+			t.Errorf("unexpected error: want nil, got %v", err)
+		}
+		if count != 3 {
+			t.Errorf("unexpected request count: want 3, got %v", count)
+		}
+		u, _ := url.Parse(client.PresignUrl(http.MethodGet, "a.txt", nil, nil, cos.AuthExpirationTime))
+		if skewed(u.Query().Get("sign")) {
+			t.Errorf("unexpected sign: got %v", u.Query().Get("sign"))
+		}
+		form := client.GetPostForm(nil)
+		if skewed("q-sign-time=" + form.Fields["q-key-time"]) {
+			t.Errorf("unexpected key time: got %v", form.Fields["q-key-time"])
+		}
+	})
+
+	t.Run("没有服务端时间", func(t *testing.T) {
+		var count int32
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(newHandler(&count, false))),
+			cos.WithRetryPolicy(cos.DefaultRetryPolicy))
+		if err := client.Ping(context.Background()); !errors.Is(err, cos.ErrRequestTimeTooSkewed) {
+			t.Errorf("unexpected error: want %v, got %v", cos.ErrRequestTimeTooSkewed, err)
+		}
+		if count != 1 {
+			t.Errorf("unexpected request count: want 1, got %v", count)
+		}
+	})
+}
@@ -0,0 +1,103 @@
+/*
+ * Copyright (c) 2025 ivfzhou
+ * tencent-cos-object-api is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package cos
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// 上传检查点文件的默认后缀。
+	uploadCheckpointSuffix = ".cos-upload.cp"
+	// 下载检查点文件的默认后缀。
+	downloadCheckpointSuffix = ".cos-download.cp"
+	// 下载临时文件的后缀。
+	downloadTmpSuffix = ".cos-download.tmp"
+)
+
+// 断点续传上传的检查点。
+type uploadCheckpoint struct {
+	// FileId 文件 ID。
+	FileId string
+	// UploadId 分片上传 ID。
+	UploadId string
+	// PartSize 分片大小。
+	PartSize int64
+	// FileSize 本地文件大小。
+	FileSize int64
+	// ModTime 本地文件的修改时间。
+	ModTime int64
+	// Parts 已上传的分片序号与 ETag。
+	Parts map[int64]string
+}
+
+// 检查点是否属于当前的上传任务。
+func (cp *uploadCheckpoint) match(fileId string, fileInfo os.FileInfo, partSize int64) bool {
+	return cp.FileId == fileId && len(cp.UploadId) > 0 && cp.PartSize == partSize &&
+		cp.FileSize == fileInfo.Size() && cp.ModTime == fileInfo.ModTime().UnixNano()
+}
+
+// 断点续传下载的检查点。
+type downloadCheckpoint struct {
+	// FileId 文件 ID。
+	FileId string
+	// ETag 下载开始时文件的 ETag。
+	ETag string
+	// FileSize 文件大小。
+	FileSize int64
+	// PartSize 分片大小。
+	PartSize int64
+	// Parts 已下载完成的分片序号，分片 n 的字节范围是 [(n-1)*PartSize, n*PartSize)。
+	Parts map[int64]bool
+}
+
+// 检查点是否属于当前的下载任务。
+func (cp *downloadCheckpoint) match(fileId, etag string, fileSize, partSize int64) bool {
+	return cp.FileId == fileId && cp.ETag == etag && cp.FileSize == fileSize && cp.PartSize == partSize &&
+		cp.Parts != nil
+}
+
+// 读取检查点文件。
+func loadCheckpoint(path string, cp any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, cp)
+}
+
+// 保存检查点文件。先写临时文件再重命名，避免进程崩溃时留下不完整的检查点。
+func saveCheckpoint(path string, cp any) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmpPath := path + ".tmp"
+	if err = os.WriteFile(tmpPath, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// 删除文件，文件不存在不算错误。
+func removeIfExists(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -15,15 +15,18 @@ package cos
 import (
 	"bytes"
 	"fmt"
-	"io"
 	"net/http"
-	"os"
 	"path/filepath"
 	"strings"
 	"sync"
-	"unsafe"
 )
 
+// 自定义元数据 HTTP 头的前缀。
+const metaHeaderPrefix = "x-cos-meta-"
+
+// 临时密钥令牌的 HTTP 头和请求参数名。
+const securityTokenHeader = "x-cos-security-token"
+
 var (
 	requestPool = sync.Pool{New: func() any {
 		return &http.Request{
@@ -31,7 +34,6 @@ var (
 			ProtoMinor: 1,
 		}
 	}}
-	bytesPool = sync.Pool{New: func() any { return make([]byte, getPartSize()) }}
 )
 
 // 获取请求体。
@@ -63,57 +65,19 @@ func rollbackRequest(req *http.Request) {
 	}
 }
 
-// 获取字节数组。
-func makeBytes() []byte {
-	return bytesPool.Get().([]byte)
-}
-
-// 回收字节数组。
-func rollbackBytes(data []byte) {
-	if int64(cap(data)) != getPartSize() {
-		data = nil
-		return
-	}
-	if cap(data) > len(data) {
-		data = unsafe.Slice(&data[0], cap(data))
-	}
-	bytesPool.Put(data)
+// 创建分片大小的字节数组池。
+func newBytesPool(partSize int64) *sync.Pool {
+	return &sync.Pool{New: func() any { return make([]byte, partSize) }}
 }
 
-// 获取分片大小。
-func getPartSize() int64 {
-	partSize := PartSize
+// 纠正分片大小。
+func suitPartSize(partSize int64) int64 {
 	if partSize < 1024*1024 {
 		return 1024 * 1024 * 8 // 一个分片最小 1MiB。
 	} else if partSize > 5*1024*1024*1024 {
 		return 5 * 1024 * 1024 * 1024 // 一个分片最大 5GiB。
 	}
-	return int64(partSize)
-}
-
-// 读取响应体并关闭。
-func readAndClose(rsp *http.Response) []byte {
-	if rsp != nil && rsp.Body != nil {
-		bs, err := io.ReadAll(rsp.Body)
-		printError(err)
-		closeRsp(rsp)
-		return bs
-	}
-	return nil
-}
-
-// 关闭流。
-func closeIO(closer io.Closer) {
-	if closer != nil {
-		printError(closer.Close())
-	}
-}
-
-// 关闭 HTTP 响应对象的响应体。
-func closeRsp(r *http.Response) {
-	if r != nil && r.Body != nil {
-		printError(r.Body.Close())
-	}
+	return partSize
 }
 
 // URL 编码。
@@ -157,20 +121,28 @@ func urlEncode(s string) string {
 	return s
 }
 
-// 向标准错误输出流打印错误信息。
-func printError(err error) {
-	if err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "tencent-cos-object-api: %v\n", err)
-	}
-}
-
 // 纠正文件 ID。
 func suitFileId(fileId string) string {
 	return strings.TrimLeft(strings.TrimLeft(filepath.Clean(strings.Trim(fileId, "/")), "."), "/")
 }
 
-// 判断文件大小是否用分片模式传输。
-func useMultipart(size int64) bool {
-	partSize := getPartSize()
-	return size > int64(MultiThreshold)*partSize || size > 5*1024*1024*1024 // 大于 5GiB，必须要用分片上传。
+// 去掉开头的 /，非空时以 / 结尾，按目录匹配文件。
+func suitDirPrefix(prefix string) string {
+	prefix = strings.TrimLeft(prefix, "/")
+	if len(prefix) > 0 && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return prefix
+}
+
+// 从响应头中提取自定义元数据，键为去掉 x-cos-meta- 前缀后的小写名称。
+func parseMetadata(header http.Header) map[string]string {
+	metadata := make(map[string]string)
+	for k, v := range header {
+		k = strings.ToLower(k)
+		if strings.HasPrefix(k, metaHeaderPrefix) && len(v) > 0 {
+			metadata[k[len(metaHeaderPrefix):]] = v[0]
+		}
+	}
+	return metadata
 }
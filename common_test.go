@@ -16,11 +16,17 @@ import (
 	"bytes"
 	"context"
 	crand "crypto/rand"
+	"encoding/xml"
 	"fmt"
 	"io"
+	"maps"
 	"math/rand"
 	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -248,3 +254,126 @@ func (w *writeCloser) Close() error {
 	}
 	return fmt.Errorf("writer already closed")
 }
+
+// MockBucket 内存中的存储桶，支持 HEAD、复制、列举、删除和批量删除。
+type MockBucket struct {
+	lock        sync.Mutex
+	files       map[string]int64
+	failCopy    map[string]bool
+	failDelete  map[string]bool
+	deleteCount int32
+}
+
+func NewMockBucket(keys ...string) *MockBucket {
+	b := &MockBucket{files: make(map[string]int64), failCopy: make(map[string]bool), failDelete: make(map[string]bool)}
+	for i, v := range keys {
+		b.files[v] = int64(i + 1)
+	}
+	return b
+}
+
+func (b *MockBucket) Keys() []string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	keys := make([]string, 0, len(b.files))
+	for k := range b.files {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func (b *MockBucket) hasFile(key string) bool {
+	_, ok := b.files[key]
+	return ok
+}
+
+func (b *MockBucket) Handle(req *http.Request) (*http.Response, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	key := strings.TrimLeft(req.URL.Path, "/")
+	query := req.URL.Query()
+	ok := func(body string) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: NewReader([]byte(body), nil, nil, nil)}, nil
+	}
+	switch {
+	case req.Method == http.MethodHead:
+		size, exists := b.files[key]
+		if !exists {
+			return &http.Response{StatusCode: http.StatusNotFound, Body: NewReader(nil, nil, nil, nil)}, nil
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Length": []string{strconv.FormatInt(size, 10)}},
+			Body:       NewReader(nil, nil, nil, nil),
+		}, nil
+	case req.Method == http.MethodPut && len(req.Header.Get("x-cos-copy-source")) > 0:
+		source := req.Header.Get("x-cos-copy-source")
+		srcKey, _ := url.PathUnescape(source[strings.Index(source, "/")+1:])
+		if b.failCopy[srcKey] {
+			return &http.Response{
+				StatusCode: http.StatusInternalServerError,
+				Body:       NewReader([]byte("<Error><Code>InternalError</Code></Error>"), nil, nil, nil),
+			}, nil
+		}
+		b.files[key] = b.files[srcKey]
+		return ok("<CopyObjectResult></CopyObjectResult>")
+	case req.Method == http.MethodGet && len(key) <= 0:
+		prefix, marker, delimiter := query.Get("prefix"), query.Get("marker"), query.Get("delimiter")
+		limit, _ := strconv.Atoi(query.Get("max-keys"))
+		if limit <= 0 {
+			limit = 1000
+		}
+		entries := make(map[string]bool)
+		for k := range b.files {
+			if !strings.HasPrefix(k, prefix) {
+				continue
+			}
+			if i := strings.Index(k[len(prefix):], delimiter); len(delimiter) > 0 && i >= 0 {
+				k = k[:len(prefix)+i+len(delimiter)]
+			}
+			if k > marker {
+				entries[k] = true
+			}
+		}
+		keys := slices.Sorted(maps.Keys(entries))
+		var res struct {
+			XMLName        xml.Name `xml:"ListBucketResult"`
+			IsTruncated    bool
+			Contents       []struct{ Key string }
+			CommonPrefixes []struct{ Prefix string }
+		}
+		if len(keys) > limit {
+			keys, res.IsTruncated = keys[:limit], true
+		}
+		for _, v := range keys {
+			if len(delimiter) > 0 && strings.HasSuffix(v, delimiter) && !b.hasFile(v) {
+				res.CommonPrefixes = append(res.CommonPrefixes, struct{ Prefix string }{v})
+			} else {
+				res.Contents = append(res.Contents, struct{ Key string }{v})
+			}
+		}
+		bs, _ := xml.Marshal(&res)
+		return ok(string(bs))
+	case req.Method == http.MethodPost && query.Has("delete"):
+		atomic.AddInt32(&b.deleteCount, 1)
+		var reqObj struct {
+			Object []struct{ Key string }
+		}
+		bs, _ := io.ReadAll(req.Body)
+		_ = xml.Unmarshal(bs, &reqObj)
+		body := "<DeleteResult>"
+		for _, v := range reqObj.Object {
+			if b.failDelete[v.Key] {
+				body += "<Error><Key>" + v.Key + "</Key><Code>AccessDenied</Code></Error>"
+				continue
+			}
+			delete(b.files, v.Key)
+		}
+		return ok(body + "</DeleteResult>")
+	case req.Method == http.MethodDelete:
+		delete(b.files, key)
+		return ok("")
+	}
+	return &http.Response{StatusCode: http.StatusBadRequest, Body: NewReader(nil, nil, nil, nil)}, nil
+}
@@ -0,0 +1,188 @@
+/*
+ * Copyright (c) 2025 ivfzhou
+ * tencent-cos-object-api is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package cos_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	cos "gitee.com/ivfzhou/tencent-cos-object-api"
+)
+
+func TestCondition(t *testing.T) {
+	t.Run("文件存在时不上传", func(t *testing.T) {
+		for _, much := range []bool{false, true} {
+			partSize := int64(1024 * 1024)
+			data := MakeBytesWithSize(1024)
+			if much {
+				data = MakeBytesWithSize(int(partSize) * 3)
+			}
+			var conditionCount, abortCount int32
+			fn := func(req *http.Request) (*http.Response, error) {
+				query := req.URL.Query()
+				if v := req.Header.Get("If-None-Match"); len(v) > 0 {
+					atomic.AddInt32(&conditionCount, 1)
+					if v != "*" {
+						t.Errorf("unexpected If-None-Match: want *, got %v", v)
+					}
+					if much && (req.Method != http.MethodPost || !query.Has("uploadId")) {
+						t.Errorf("unexpected conditional request: %v %v", req.Method, req.URL)
+					}
+					return &http.Response{
+						StatusCode: http.StatusPreconditionFailed,
+						Body: NewReader([]byte("<Error><Code>PreconditionFailed</Code></Error>"),
+							nil, nil, nil),
+					}, nil
+				}
+				switch {
+				case req.Method == http.MethodPost && query.Has("uploads"):
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body: NewReader([]byte("<InitiateMultipartUploadResult><UploadId>upload id"+
+							"</UploadId></InitiateMultipartUploadResult>"), nil, nil, nil),
+					}, nil
+				case req.Method == http.MethodGet:
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       NewReader([]byte("<ListPartsResult></ListPartsResult>"), nil, nil, nil),
+					}, nil
+				case req.Method == http.MethodDelete:
+					atomic.AddInt32(&abortCount, 1)
+				}
+				_, _ = io.Copy(io.Discard, req.Body)
+				return &http.Response{StatusCode: http.StatusOK, Body: NewReader(nil, nil, nil, nil)}, nil
+			}
+			client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)),
+				cos.WithPartSize(partSize), cos.WithMultiThreshold(2), cos.WithNonCheckCrc64())
+			err := client.Upload(context.Background(), "a.txt", data, cos.WithIfNoneMatch("*"))
+			if !errors.Is(err, cos.ErrPreconditionFailed) {
+				t.Errorf("unexpected error: want %v, got %v", cos.ErrPreconditionFailed, err)
+			}
+			if n := atomic.LoadInt32(&conditionCount); n != 1 {
+				t.Errorf("unexpected condition count: want 1, got %v", n)
+			}
+			if much {
+				time.Sleep(100 * time.Millisecond)
+				if n := atomic.LoadInt32(&abortCount); n != 1 {
+					t.Errorf("unexpected abort count: want 1, got %v", n)
+				}
+			}
+		}
+	})
+
+	t.Run("文件未修改时不下载", func(t *testing.T) {
+		since := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+		fn := func(req *http.Request) (*http.Response, error) {
+			if req.Method != http.MethodHead {
+				t.Errorf("unexpected method: want HEAD, got %v", req.Method)
+			}
+			if v := req.Header.Get("If-Modified-Since"); v != "Thu, 02 Jan 2025 03:04:05 GMT" {
+				t.Errorf("unexpected If-Modified-Since: got %v", v)
+			}
+			return &http.Response{StatusCode: http.StatusNotModified, Body: NewReader(nil, nil, nil, nil)}, nil
+		}
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)))
+		_, _, err := client.Download(context.Background(), "a.txt", cos.WithIfModifiedSince(since))
+		if !errors.Is(err, cos.ErrNotModified) {
+			t.Errorf("unexpected error: want %v, got %v", cos.ErrNotModified, err)
+		}
+		err = client.DownloadToDisk(context.Background(), "a.txt", t.TempDir()+"/a.txt", cos.WithIfModifiedSince(since))
+		if !errors.Is(err, cos.ErrNotModified) {
+			t.Errorf("unexpected error: want %v, got %v", cos.ErrNotModified, err)
+		}
+		_, err = client.Info(context.Background(), "a.txt", cos.WithIfModifiedSince(since))
+		if !errors.Is(err, cos.ErrNotModified) || errors.Is(err, cos.ErrNotExists) {
+			t.Errorf("unexpected error: want %v, got %v", cos.ErrNotModified, err)
+		}
+	})
+
+	t.Run("ETag 一致时删除", func(t *testing.T) {
+		fn := func(req *http.Request) (*http.Response, error) {
+			if v := req.Header.Get("If-Match"); v != `"etag"` {
+				t.Errorf("unexpected If-Match: got %v", v)
+			}
+			return &http.Response{StatusCode: http.StatusPreconditionFailed, Body: NewReader(nil, nil, nil, nil)}, nil
+		}
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)))
+		err := client.Delete(context.Background(), "a.txt", cos.WithIfMatch(`"etag"`))
+		if !errors.Is(err, cos.ErrPreconditionFailed) {
+			t.Errorf("unexpected error: want %v, got %v", cos.ErrPreconditionFailed, err)
+		}
+	})
+
+	t.Run("调用参数不影响客户端", func(t *testing.T) {
+		var want atomic.Value
+		fn := func(req *http.Request) (*http.Response, error) {
+			if v := req.Header.Get("If-Match"); v != want.Load().(string) {
+				t.Errorf("unexpected If-Match: want %v, got %v", want.Load(), v)
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: NewReader(nil, nil, nil, nil)}, nil
+		}
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)))
+		want.Store("etag")
+		if err := client.Delete(context.Background(), "a.txt", cos.WithIfMatch("etag")); err != nil {
+			t.Errorf("unexpected error: want nil, got %v", err)
+		}
+		want.Store("")
+		if err := client.Delete(context.Background(), "a.txt"); err != nil {
+			t.Errorf("unexpected error: want nil, got %v", err)
+		}
+	})
+
+	t.Run("下载请求都带条件", func(t *testing.T) {
+		for _, much := range []bool{false, true} {
+			partSize := int64(1024 * 1024)
+			data := MakeBytesWithSize(1024)
+			if much {
+				data = MakeBytesWithSize(int(partSize) * 3)
+			}
+			var getCount int32
+			fn := func(req *http.Request) (*http.Response, error) {
+				if v := req.Header.Get("If-Match"); v != `"etag"` {
+					t.Errorf("unexpected If-Match: want \"etag\", got %v", v)
+				}
+				if req.Method == http.MethodHead {
+					header := http.Header{}
+					header.Set("Content-Length", strconv.Itoa(len(data)))
+					return &http.Response{StatusCode: http.StatusOK, Header: header, Body: NewReader(nil, nil, nil, nil)}, nil
+				}
+				atomic.AddInt32(&getCount, 1)
+				if much && len(req.Header.Get("Range")) <= 0 {
+					t.Errorf("unexpected range: want range request, got none")
+				}
+				return &http.Response{StatusCode: http.StatusPreconditionFailed, Body: NewReader(nil, nil, nil, nil)}, nil
+			}
+			client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)),
+				cos.WithPartSize(partSize), cos.WithMultiThreshold(2))
+			err := client.DownloadToWriterWithSize(context.Background(), "a.txt", int64(len(data)), io.Discard,
+				cos.WithIfMatch(`"etag"`))
+			if !errors.Is(err, cos.ErrPreconditionFailed) {
+				t.Errorf("unexpected error: want %v, got %v", cos.ErrPreconditionFailed, err)
+			}
+			wa := NewWriterAt(func(bs []byte, of int64) (int, error) { return len(bs), nil })
+			err = client.DownloadToWriterAt(context.Background(), "a.txt", wa, cos.WithIfMatch(`"etag"`))
+			if !errors.Is(err, cos.ErrPreconditionFailed) {
+				t.Errorf("unexpected error: want %v, got %v", cos.ErrPreconditionFailed, err)
+			}
+			if n := atomic.LoadInt32(&getCount); n < 2 {
+				t.Errorf("unexpected get count: want >=2, got %v", n)
+			}
+		}
+	})
+}
@@ -0,0 +1,23 @@
+/*
+ * Copyright (c) 2025 ivfzhou
+ * tencent-cos-object-api is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package cos
+
+import "context"
+
+// Copier 在服务端复制文件，数据不经过本地。opts 可覆盖客户端的参数，只对本次复制生效。
+type Copier interface {
+	// Copy 复制文件。源文件默认在本存储桶，可用 WithCopySourceHost 指定其它存储桶。
+	// 默认复制源文件的元数据，使用 WithReplaceMetadata 时改用 WithContentType、WithMetadata 等设置的元数据。
+	// 源文件超过 5GiB 时使用分片复制。
+	Copy(ctx context.Context, srcId, dstId string, opts ...option) error
+}
@@ -0,0 +1,249 @@
+/*
+ * Copyright (c) 2025 ivfzhou
+ * tencent-cos-object-api is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package cos
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	gu "gitee.com/ivfzhou/goroutine-util"
+)
+
+// 简单复制支持的最大文件大小，超过时使用分片复制。
+const maxCopySize = 5 * 1024 * 1024 * 1024
+
+// 分片上传最多的分片数量。
+const maxPartCount = 10000
+
+type copyImpl struct {
+	*baseImpl
+	*multiUploadImpl
+}
+
+// Copy 复制文件。
+func (c *copyImpl) Copy(ctx context.Context, srcId, dstId string, opts ...option) error {
+	srcId = suitFileId(srcId)
+	if len(srcId) <= 0 {
+		return errors.New("srcId is invalid")
+	}
+	dstId = suitFileId(dstId)
+	if len(dstId) <= 0 {
+		return errors.New("dstId is invalid")
+	}
+	return c.with(opts).copyKey(ctx, srcId, dstId)
+}
+
+// 使用调用参数覆盖客户端参数。
+func (c *copyImpl) with(opts []option) *copyImpl {
+	b := c.baseImpl.with(opts)
+	if b == c.baseImpl {
+		return c
+	}
+	return &copyImpl{b, &multiUploadImpl{b}}
+}
+
+// 复制文件，文件 ID 不做处理。
+func (c *copyImpl) copyKey(ctx context.Context, srcId, dstId string) error {
+	// 获取源文件信息。
+	src := c.copySource()
+	rsp, err := src.head(ctx, srcId)
+	if err != nil {
+		return err
+	}
+	size := rsp.ContentLength
+	if size <= 0 {
+		size, _ = strconv.ParseInt(rsp.Header.Get("Content-Length"), 10, 64)
+	}
+	source := src.host + (&url.URL{Path: src.objectPath(srcId)}).EscapedPath()
+	if len(c.versionId) > 0 {
+		source += "?versionId=" + url.QueryEscape(c.versionId)
+	}
+
+	// 是否使用分片复制。
+	var crc string
+	if size > maxCopySize {
+		crc, err = c.multiCopy(ctx, source, dstId, size, rsp.Header)
+	} else {
+		crc, err = c.copy(ctx, source, dstId)
+	}
+	if err != nil {
+		return err
+	}
+
+	// 比较源文件与目标文件的 CRC64。
+	if srcCrc, ok := parseCrc64(rsp.Header.Get("x-cos-hash-crc64ecma")); ok && !c.nonCheckCrc64 {
+		return checkCrc64(dstId, srcCrc, crc)
+	}
+	return nil
+}
+
+// 源文件所在存储桶的客户端。
+func (c *baseImpl) copySource() *baseImpl {
+	if len(c.copySourceHost) <= 0 || c.copySourceHost == c.host {
+		return c
+	}
+	src := *c
+	src.host = c.copySourceHost
+	src.domain = "" // 自定义域名属于本存储桶。
+	return &src
+}
+
+// 简单复制，返回目标文件的 CRC64。
+func (c *copyImpl) copy(ctx context.Context, source, dstId string) (string, error) {
+	// 生成请求头。
+	header := c.mergeUploadHeader(nil)
+	c.encryption.setCopySourceHeader(header)
+	header.Set("x-cos-copy-source", source)
+	if c.replaceMetadata {
+		header.Set("x-cos-metadata-directive", "Replaced")
+	} else {
+		header.Set("x-cos-metadata-directive", "Copy")
+	}
+	req := c.genReq(http.MethodPut, dstId, nil, header, nil)
+
+	// 发送 HTTP 请求。
+	rsp, err := c.sendHttp(ctx, OperationPutObjectCopy, req)
+	if err != nil {
+		return "", err
+	}
+	rspBody, err := io.ReadAll(rsp.Body)
+	c.closeRsp(rsp)
+	if err != nil {
+		return "", err
+	}
+
+	// 复制失败时响应码也可能是 200，错误信息在响应体中。
+	return parseCopyResult(rsp, http.MethodPut, dstId, rspBody, "CopyObjectResult")
+}
+
+// 分片复制，返回目标文件的 CRC64。srcHeader 是源文件的响应头，复制元数据时用于初始化分片上传。
+func (c *copyImpl) multiCopy(ctx context.Context, source, dstId string, size int64, srcHeader http.Header) (
+	string, error) {
+
+	// 复制元数据时，分片复制不会自动带上源文件的元数据，需要在初始化时设置。
+	m := c.multiUploadImpl
+	if !c.replaceMetadata {
+		b := *c.baseImpl
+		b.uploadHeader = http.Header{}
+		for k, v := range srcHeader {
+			switch k = http.CanonicalHeaderKey(k); {
+			case k == "Content-Type", k == "Content-Disposition", k == "Cache-Control", k == "Content-Encoding",
+				k == "Expires", strings.HasPrefix(strings.ToLower(k), metaHeaderPrefix):
+				b.uploadHeader[k] = v
+			}
+		}
+		for k, v := range c.uploadHeader {
+			if strings.EqualFold(k, "x-cos-storage-class") {
+				b.uploadHeader[k] = v
+			}
+		}
+		m = &multiUploadImpl{&b}
+	}
+
+	// 初始化分片上传。
+	uploadId, err := m.InitMultiUpload(ctx, dstId)
+	if err != nil {
+		return "", err
+	}
+
+	// 并发复制分片，分片数量不能超过上限。
+	partSize := max(c.partSize, (size+maxPartCount-1)/maxPartCount)
+	type data struct {
+		num, offset, end int64
+	}
+	run, wait := gu.NewRunner(ctx, c.numRoutines, func(ctx context.Context, t *data) error {
+		return c.copyPart(ctx, source, dstId, uploadId, t.num, t.offset, t.end)
+	})
+	for num, offset := int64(1), int64(0); offset < size; num, offset = num+1, offset+partSize {
+		if err = run(&data{num, offset, min(offset+partSize, size) - 1}, false); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = wait(true)
+	}
+	if err != nil {
+		noCancelCtx := context.WithoutCancel(ctx)
+		go func() { // 出错就丢弃已复制的分片。
+			_ = wait(false)
+			c.tryAbortMultiUpload(noCancelCtx, dstId, uploadId)
+		}()
+		return "", err
+	}
+
+	// 合并分片。
+	result, err := c.completeMultiUpload(ctx, dstId, uploadId, nil)
+	if err != nil {
+		noCancelCtx := context.WithoutCancel(ctx)
+		go func() { // 出错就丢弃已复制的分片。
+			c.tryAbortMultiUpload(noCancelCtx, dstId, uploadId)
+		}()
+		return "", err
+	}
+
+	return result.Crc64, nil
+}
+
+// 复制分片。
+func (c *copyImpl) copyPart(ctx context.Context, source, dstId, uploadId string, partNumber, offset,
+	end int64) error {
+
+	// 生成请求体。
+	query := url.Values{}
+	query.Set("uploadId", uploadId)
+	query.Set("partNumber", strconv.FormatInt(partNumber, 10))
+	header := c.mergeEncryptionHeader(nil)
+	c.encryption.setCopySourceHeader(header)
+	header.Set("x-cos-copy-source", source)
+	header.Set("x-cos-copy-source-range", fmt.Sprintf("bytes=%d-%d", offset, end))
+	req := c.genReq(http.MethodPut, dstId, query, header, nil)
+
+	// 发送 HTTP 请求。
+	rsp, err := c.sendHttp(ctx, OperationUploadPartCopy, req)
+	if err != nil {
+		return err
+	}
+	rspBody, err := io.ReadAll(rsp.Body)
+	c.closeRsp(rsp)
+	if err != nil {
+		return err
+	}
+
+	_, err = parseCopyResult(rsp, http.MethodPut, dstId, rspBody, "CopyPartResult")
+	return err
+}
+
+// 解析复制的响应体，返回 CRC64。响应体是错误信息时返回错误。
+func parseCopyResult(rsp *http.Response, method, fileId string, body []byte, name string) (string, error) {
+	var rspData struct {
+		XMLName xml.Name
+		CRC64   string
+	}
+	if err := xml.Unmarshal(body, &rspData); err != nil {
+		return "", err
+	}
+	switch rspData.XMLName.Local {
+	case name:
+		return rspData.CRC64, nil
+	case "Error":
+		return "", newError(method, fileId, rsp, body)
+	}
+	return "", fmt.Errorf("unexpected copy result %s", string(body))
+}
@@ -0,0 +1,238 @@
+/*
+ * Copyright (c) 2025 ivfzhou
+ * tencent-cos-object-api is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package cos_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	cos "gitee.com/ivfzhou/tencent-cos-object-api"
+)
+
+func TestCopy(t *testing.T) {
+	t.Run("简单复制", func(t *testing.T) {
+		for _, replace := range []bool{false, true} {
+			srcHost := "src-appId.cos.region.myqcloud.com"
+			var putCount int32
+			atomic.StoreInt32(&CloseCount, 0)
+			fn := func(req *http.Request) (*http.Response, error) {
+				auth := req.Header.Get("Authorization")
+				if !CheckAuthorization(auth, req.URL.Path, req.Method, req.Header, req.URL.Query()) {
+					t.Errorf("unexpected auth: got %v", auth)
+				}
+				switch req.Method {
+				case http.MethodHead:
+					if req.Host != srcHost {
+						t.Errorf("unexpected host: want %v, got %v", srcHost, req.Host)
+					}
+					if req.URL.Path != "/src dir/file" {
+						t.Errorf("unexpected path: want /src dir/file, got %v", req.URL.Path)
+					}
+					header := http.Header{}
+					header.Set("Content-Length", "1024")
+					header.Set("x-cos-hash-crc64ecma", "123")
+					return &http.Response{StatusCode: http.StatusOK, Header: header, Body: NewReader(nil, nil, nil, nil)}, nil
+				case http.MethodPut:
+					atomic.AddInt32(&putCount, 1)
+					if req.Host != host {
+						t.Errorf("unexpected host: want %v, got %v", host, req.Host)
+					}
+					if req.URL.Path != "/dst/file" {
+						t.Errorf("unexpected path: want /dst/file, got %v", req.URL.Path)
+					}
+					want := srcHost + "/src%20dir/file"
+					if v := req.Header.Get("x-cos-copy-source"); v != want {
+						t.Errorf("unexpected copy source: want %v, got %v", want, v)
+					}
+					wantDirective, wantType := "Copy", ""
+					if replace {
+						wantDirective, wantType = "Replaced", "text/plain"
+					}
+					if v := req.Header.Get("x-cos-metadata-directive"); v != wantDirective {
+						t.Errorf("unexpected metadata directive: want %v, got %v", wantDirective, v)
+					}
+					if v := req.Header.Get("Content-Type"); replace && v != wantType {
+						t.Errorf("unexpected content type: want %v, got %v", wantType, v)
+					}
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body: NewReader([]byte("<CopyObjectResult><ETag>etag</ETag><CRC64>123</CRC64>"+
+							"</CopyObjectResult>"), nil, nil, nil),
+					}, nil
+				}
+				t.Errorf("unexpected method: got %v", req.Method)
+				return nil, nil
+			}
+			client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)))
+			var err error
+			if replace {
+				err = client.Copy(context.Background(), "src dir/file", "dst/file", cos.WithCopySourceHost(srcHost),
+					cos.WithReplaceMetadata(), cos.WithContentType("text/plain"))
+			} else {
+				err = client.Copy(context.Background(), "src dir/file", "dst/file", cos.WithCopySourceHost(srcHost))
+			}
+			if err != nil {
+				t.Errorf("unexpected error: want nil, got %v", err)
+			}
+			if n := atomic.LoadInt32(&putCount); n != 1 {
+				t.Errorf("unexpected put count: want 1, got %v", n)
+			}
+			if closeCount := atomic.LoadInt32(&CloseCount); closeCount != 0 {
+				t.Errorf("unexpected close count: want 0, got %v", closeCount)
+			}
+		}
+	})
+
+	t.Run("分片复制", func(t *testing.T) {
+		size := int64(6*1024*1024*1024 + 1)
+		partSize := int64(1024 * 1024 * 1024)
+		uploadId := "expected upload id"
+		ranges := sync.Map{}
+		var completed int32
+		atomic.StoreInt32(&CloseCount, 0)
+		fn := func(req *http.Request) (*http.Response, error) {
+			auth := req.Header.Get("Authorization")
+			if !CheckAuthorization(auth, req.URL.Path, req.Method, req.Header, req.URL.Query()) {
+				t.Errorf("unexpected auth: got %v", auth)
+			}
+			query := req.URL.Query()
+			switch {
+			case req.Method == http.MethodHead:
+				header := http.Header{}
+				header.Set("Content-Length", strconv.FormatInt(size, 10))
+				header.Set("Content-Type", "image/png")
+				header.Set("x-cos-meta-owner", "ivfzhou")
+				header.Set("x-cos-hash-crc64ecma", "456")
+				return &http.Response{StatusCode: http.StatusOK, Header: header, Body: NewReader(nil, nil, nil, nil)}, nil
+			case req.Method == http.MethodPost && query.Has("uploads"):
+				if v := req.Header.Get("Content-Type"); v != "image/png" {
+					t.Errorf("unexpected content type: want image/png, got %v", v)
+				}
+				if v := req.Header.Get("x-cos-meta-owner"); v != "ivfzhou" {
+					t.Errorf("unexpected meta: want ivfzhou, got %v", v)
+				}
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body: NewReader([]byte("<InitiateMultipartUploadResult><UploadId>"+uploadId+
+						"</UploadId></InitiateMultipartUploadResult>"), nil, nil, nil),
+				}, nil
+			case req.Method == http.MethodPut:
+				if v := query.Get("uploadId"); v != uploadId {
+					t.Errorf("unexpected upload id: want %v, got %v", uploadId, v)
+				}
+				if v := req.Header.Get("x-cos-copy-source"); v != host+"/src/file" {
+					t.Errorf("unexpected copy source: want %v, got %v", host+"/src/file", v)
+				}
+				var begin, end int64
+				if _, err := fmt.Sscanf(req.Header.Get("x-cos-copy-source-range"), "bytes=%d-%d", &begin, &end); err != nil {
+					t.Errorf("unexpected error: want nil, got %v", err)
+				}
+				num, _ := strconv.ParseInt(query.Get("partNumber"), 10, 64)
+				ranges.Store(begin, [2]int64{end, num})
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       NewReader([]byte("<CopyPartResult><ETag>etag</ETag></CopyPartResult>"), nil, nil, nil),
+				}, nil
+			case req.Method == http.MethodGet:
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       NewReader([]byte("<ListPartsResult></ListPartsResult>"), nil, nil, nil),
+				}, nil
+			case req.Method == http.MethodPost:
+				atomic.AddInt32(&completed, 1)
+				header := http.Header{}
+				header.Set("x-cos-hash-crc64ecma", "456")
+				return &http.Response{StatusCode: http.StatusOK, Header: header, Body: NewReader(nil, nil, nil, nil)}, nil
+			}
+			t.Errorf("unexpected method: got %v", req.Method)
+			return nil, nil
+		}
+		err := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)), cos.WithPartSize(partSize)).
+			Copy(context.Background(), "/src/file", "/dst/file")
+		if err != nil {
+			t.Errorf("unexpected error: want nil, got %v", err)
+		}
+		var begins []int
+		ranges.Range(func(key, _ any) bool {
+			begins = append(begins, int(key.(int64)))
+			return true
+		})
+		sort.Ints(begins)
+		next := int64(0)
+		for i, v := range begins {
+			value, _ := ranges.Load(int64(v))
+			pair := value.([2]int64)
+			if int64(v) != next || pair[1] != int64(i+1) {
+				t.Errorf("unexpected range: want %v part %v, got %v part %v", next, i+1, v, pair[1])
+			}
+			next = pair[0] + 1
+		}
+		if next != size {
+			t.Errorf("unexpected range end: want %v, got %v", size, next)
+		}
+		if n := atomic.LoadInt32(&completed); n != 1 {
+			t.Errorf("unexpected complete count: want 1, got %v", n)
+		}
+		if closeCount := atomic.LoadInt32(&CloseCount); closeCount != 0 {
+			t.Errorf("unexpected close count: want 0, got %v", closeCount)
+		}
+	})
+
+	t.Run("复制失败", func(t *testing.T) {
+		for _, mismatch := range []bool{false, true} {
+			fn := func(req *http.Request) (*http.Response, error) {
+				if req.Method == http.MethodHead {
+					header := http.Header{}
+					header.Set("Content-Length", "1024")
+					header.Set("x-cos-hash-crc64ecma", "123")
+					return &http.Response{StatusCode: http.StatusOK, Header: header, Body: NewReader(nil, nil, nil, nil)}, nil
+				}
+				body := "<Error><Code>InternalError</Code><Message>copy failed</Message></Error>"
+				if mismatch {
+					body = "<CopyObjectResult><CRC64>124</CRC64></CopyObjectResult>"
+				}
+				return &http.Response{StatusCode: http.StatusOK, Body: NewReader([]byte(body), nil, nil, nil)}, nil
+			}
+			err := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn))).
+				Copy(context.Background(), "/src/file", "/dst/file")
+			if mismatch {
+				if !errors.Is(err, cos.ErrChecksumMismatch) {
+					t.Errorf("unexpected error: want %v, got %v", cos.ErrChecksumMismatch, err)
+				}
+				continue
+			}
+			var cosErr *cos.Error
+			if !errors.As(err, &cosErr) || cosErr.Code != "InternalError" {
+				t.Errorf("unexpected error: want InternalError, got %v", err)
+			}
+		}
+	})
+
+	t.Run("源文件不存在", func(t *testing.T) {
+		fn := func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusNotFound, Body: NewReader(nil, nil, nil, nil)}, nil
+		}
+		err := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn))).
+			Copy(context.Background(), "/src/file", "/dst/file")
+		if !errors.Is(err, cos.ErrNotExists) {
+			t.Errorf("unexpected error: want %v, got %v", cos.ErrNotExists, err)
+		}
+	})
+}
@@ -0,0 +1,226 @@
+/*
+ * Copyright (c) 2025 ivfzhou
+ * tencent-cos-object-api is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package cos
+
+import (
+	"hash/crc64"
+	"io"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// CRC64-ECMA 的查找表。
+var crc64Table = crc64.MakeTable(crc64.ECMA)
+
+// 计算 CRC64 的读取流。
+type crc64Reader struct {
+	r   io.Reader
+	crc uint64
+	n   int64
+}
+
+// 分片的 CRC64 校验值，用于合并出整个文件的校验值。
+type crc64Parts struct {
+	lock  sync.Mutex
+	parts map[int64]crc64Part
+}
+
+type crc64Part struct {
+	crc  uint64
+	size int64
+}
+
+// 读取完毕时校验 CRC64 的读取流。
+type crc64ReadCloser struct {
+	*crc64Reader
+	closer io.Closer
+	fileId string
+	remote uint64
+}
+
+// Read 读取数据，并累计校验值。
+func (r *crc64Reader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.crc = crc64.Update(r.crc, crc64Table, p[:n])
+	r.n += int64(n)
+	return n, err
+}
+
+// Seek 重放请求体时从头计算校验值。
+func (r *crc64Reader) Seek(offset int64, whence int) (int64, error) {
+	seeker, ok := r.r.(io.Seeker)
+	if !ok {
+		return 0, errSeekUnsupported
+	}
+	n, err := seeker.Seek(offset, whence)
+	if err == nil && (offset != 0 || whence != io.SeekCurrent) {
+		r.crc, r.n = 0, 0
+	}
+	return n, err
+}
+
+// Read 读取数据，读取完毕时校验。
+func (r *crc64ReadCloser) Read(p []byte) (int, error) {
+	n, err := r.crc64Reader.Read(p)
+	if err == io.EOF && r.crc != r.remote {
+		return n, &ChecksumError{FileId: r.fileId, Local: r.crc, Remote: r.remote}
+	}
+	return n, err
+}
+
+// Close 关闭读取流。
+func (r *crc64ReadCloser) Close() error {
+	return r.closer.Close()
+}
+
+// 记录分片的校验值。
+func (ps *crc64Parts) add(partNumber int64, crc uint64, size int64) {
+	if ps == nil {
+		return
+	}
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+	ps.parts[partNumber] = crc64Part{crc, size}
+}
+
+// 按分片序号合并出整个文件的校验值。
+func (ps *crc64Parts) sum() uint64 {
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+	nums := make([]int64, 0, len(ps.parts))
+	for k := range ps.parts {
+		nums = append(nums, k)
+	}
+	slices.Sort(nums)
+	crc := uint64(0)
+	for _, v := range nums {
+		crc = crc64Combine(crc, ps.parts[v].crc, ps.parts[v].size)
+	}
+	return crc
+}
+
+// 与服务端返回的校验值比较。服务端没有返回校验值时不校验。
+func (ps *crc64Parts) check(fileId, remote string) error {
+	if ps == nil {
+		return nil
+	}
+	return checkCrc64(fileId, ps.sum(), remote)
+}
+
+// 获取分片校验值的记录器，不校验 CRC64 时返回 nil。
+func (c *baseImpl) newCrc64Parts() *crc64Parts {
+	if c.nonCheckCrc64 {
+		return nil
+	}
+	return &crc64Parts{parts: make(map[int64]crc64Part)}
+}
+
+// 包装读取流，计算 CRC64。不校验 CRC64 时返回 nil。
+func (c *baseImpl) crc64Reader(r io.Reader) *crc64Reader {
+	if c.nonCheckCrc64 {
+		return nil
+	}
+	return &crc64Reader{r: r}
+}
+
+// 包装下载的读取流，读取完毕时与服务端的校验值比较。不校验 CRC64 或服务端没有返回校验值时返回原读取流。
+func (c *baseImpl) crc64ReadCloser(rc io.ReadCloser, fileId, remote string) io.ReadCloser {
+	crc, ok := parseCrc64(remote)
+	if c.nonCheckCrc64 || !ok {
+		return rc
+	}
+	return &crc64ReadCloser{&crc64Reader{r: rc}, rc, fileId, crc}
+}
+
+// 与服务端返回的校验值比较。服务端没有返回校验值时不校验。
+func checkCrc64(fileId string, local uint64, remote string) error {
+	crc, ok := parseCrc64(remote)
+	if !ok || local == crc {
+		return nil
+	}
+	return &ChecksumError{FileId: fileId, Local: local, Remote: crc}
+}
+
+// 解析服务端返回的校验值。
+func parseCrc64(s string) (uint64, bool) {
+	if len(s) <= 0 {
+		return 0, false
+	}
+	crc, err := strconv.ParseUint(s, 10, 64)
+	return crc, err == nil
+}
+
+// 计算读取流的 CRC64。
+func readCrc64(r io.Reader) (uint64, error) {
+	hash := crc64.New(crc64Table)
+	_, err := io.Copy(hash, r)
+	return hash.Sum64(), err
+}
+
+// 合并两段数据的 CRC64，crc2 是长度为 len2 的后一段数据的校验值。参考 zlib 的 crc32_combine。
+func crc64Combine(crc1, crc2 uint64, len2 int64) uint64 {
+	if len2 <= 0 {
+		return crc1
+	}
+
+	// odd 是补一个零比特的运算矩阵。
+	even := make([]uint64, 64)
+	odd := make([]uint64, 64)
+	odd[0] = crc64.ECMA
+	row := uint64(1)
+	for n := 1; n < 64; n++ {
+		odd[n] = row
+		row <<= 1
+	}
+	gf2MatrixSquare(even, odd) // 补两个零比特。
+	gf2MatrixSquare(odd, even) // 补四个零比特。
+
+	// 按 len2 的二进制位补零字节。
+	for {
+		gf2MatrixSquare(even, odd)
+		if len2&1 != 0 {
+			crc1 = gf2MatrixTimes(even, crc1)
+		}
+		len2 >>= 1
+		if len2 == 0 {
+			break
+		}
+		gf2MatrixSquare(odd, even)
+		if len2&1 != 0 {
+			crc1 = gf2MatrixTimes(odd, crc1)
+		}
+		len2 >>= 1
+		if len2 == 0 {
+			break
+		}
+	}
+
+	return crc1 ^ crc2
+}
+
+func gf2MatrixTimes(mat []uint64, vec uint64) uint64 {
+	var sum uint64
+	for i := 0; vec != 0; i, vec = i+1, vec>>1 {
+		if vec&1 != 0 {
+			sum ^= mat[i]
+		}
+	}
+	return sum
+}
+
+func gf2MatrixSquare(square, mat []uint64) {
+	for n := range 64 {
+		square[n] = gf2MatrixTimes(mat, mat[n])
+	}
+}
@@ -0,0 +1,172 @@
+/*
+ * Copyright (c) 2025 ivfzhou
+ * tencent-cos-object-api is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package cos_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"hash/crc64"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	cos "gitee.com/ivfzhou/tencent-cos-object-api"
+)
+
+func TestCrc64(t *testing.T) {
+	partSize := int64(1024 * 1024)
+	table := crc64.MakeTable(crc64.ECMA)
+	makeData := func(much bool) []byte {
+		if much {
+			return MakeBytesWithSize(int(partSize)*(rand.Intn(3)+3) + rand.Intn(1024) + 1)
+		}
+		return MakeBytesWithSize(rand.Intn(1024) + 1)
+	}
+
+	t.Run("上传校验", func(t *testing.T) {
+		for _, much := range []bool{false, true} {
+			for _, wrong := range []bool{false, true} {
+				data := makeData(much)
+				crc := crc64.Checksum(data, table)
+				if wrong {
+					crc++
+				}
+				atomic.StoreInt32(&CloseCount, 0)
+				fn := func(req *http.Request) (*http.Response, error) {
+					query := req.URL.Query()
+					header := http.Header{}
+					switch {
+					case req.Method == http.MethodPost && query.Has("uploads"):
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body: NewReader([]byte("<InitiateMultipartUploadResult><UploadId>upload id"+
+								"</UploadId></InitiateMultipartUploadResult>"), nil, nil, nil),
+						}, nil
+					case req.Method == http.MethodGet:
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body:       NewReader([]byte("<ListPartsResult></ListPartsResult>"), nil, nil, nil),
+						}, nil
+					case req.Method == http.MethodPut:
+						if _, err := io.Copy(io.Discard, req.Body); err != nil {
+							t.Errorf("unexpected error: want nil, got %v", err)
+						}
+						if !query.Has("partNumber") {
+							header.Set("x-cos-hash-crc64ecma", strconv.FormatUint(crc, 10))
+						}
+					case req.Method == http.MethodPost:
+						header.Set("x-cos-hash-crc64ecma", strconv.FormatUint(crc, 10))
+					}
+					return &http.Response{StatusCode: http.StatusOK, Header: header, Body: NewReader(nil, nil, nil, nil)}, nil
+				}
+				client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)),
+					cos.WithPartSize(partSize), cos.WithMultiThreshold(2))
+				err := client.UploadFromReaderWithSize(context.Background(), "/ivfzhou_test_file",
+					int64(len(data)), bytes.NewReader(data))
+				CheckCrc64Error(t, err, wrong)
+				if much {
+					err = client.UploadFromReader(context.Background(), "/ivfzhou_test_file", bytes.NewReader(data))
+					CheckCrc64Error(t, err, wrong)
+				}
+				err = client.Upload(context.Background(), "/ivfzhou_test_file", data, cos.WithNonCheckCrc64())
+				if err != nil {
+					t.Errorf("unexpected error: want nil, got %v", err)
+				}
+				if closeCount := atomic.LoadInt32(&CloseCount); closeCount != 0 {
+					t.Errorf("unexpected close count: want 0, got %v", closeCount)
+				}
+			}
+		}
+	})
+
+	t.Run("下载校验", func(t *testing.T) {
+		for _, much := range []bool{false, true} {
+			for _, wrong := range []bool{false, true} {
+				data := makeData(much)
+				crc := crc64.Checksum(data, table)
+				if wrong {
+					crc++
+				}
+				atomic.StoreInt32(&CloseCount, 0)
+				fn := func(req *http.Request) (*http.Response, error) {
+					header := http.Header{}
+					header.Set("x-cos-hash-crc64ecma", strconv.FormatUint(crc, 10))
+					if req.Method == http.MethodHead {
+						header.Set("Content-Length", fmt.Sprintf("%d", len(data)))
+						return &http.Response{StatusCode: http.StatusOK, Header: header, Body: NewReader(nil, nil, nil, nil)}, nil
+					}
+					begin, end := int64(0), int64(len(data)-1)
+					if v := req.Header.Get("Range"); len(v) > 0 {
+						if _, err := fmt.Sscanf(v, "bytes=%d-%d", &begin, &end); err != nil {
+							t.Errorf("unexpected error: want nil, got %v", err)
+						}
+					}
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Header:     header,
+						Body:       NewReader(data[begin:end+1], nil, nil, nil),
+					}, nil
+				}
+				client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)),
+					cos.WithPartSize(partSize), cos.WithMultiThreshold(2))
+				rc, _, err := client.Download(context.Background(), "/ivfzhou_test_file")
+				if err != nil {
+					t.Fatalf("unexpected error: want nil, got %v", err)
+				}
+				_, err = io.Copy(io.Discard, rc)
+				CheckCrc64Error(t, err, wrong)
+				if err = rc.Close(); err != nil {
+					t.Errorf("unexpected error: want nil, got %v", err)
+				}
+				wa := NewWriterAt(func(bs []byte, of int64) (int, error) { return len(bs), nil })
+				err = client.DownloadToWriterAt(context.Background(), "/ivfzhou_test_file", wa)
+				CheckCrc64Error(t, err, wrong)
+				err = client.DownloadToWriterWithSize(context.Background(), "/ivfzhou_test_file", int64(len(data)),
+					io.Discard)
+				CheckCrc64Error(t, err, wrong)
+				err = client.DownloadToWriter(context.Background(), "/ivfzhou_test_file", io.Discard,
+					cos.WithNonCheckCrc64())
+				if err != nil {
+					t.Errorf("unexpected error: want nil, got %v", err)
+				}
+				if closeCount := atomic.LoadInt32(&CloseCount); closeCount != 0 {
+					t.Errorf("unexpected close count: want 0, got %v", closeCount)
+				}
+			}
+		}
+	})
+}
+
+func CheckCrc64Error(t *testing.T, err error, wrong bool) {
+	t.Helper()
+	if !wrong {
+		if err != nil {
+			t.Errorf("unexpected error: want nil, got %v", err)
+		}
+		return
+	}
+	if !errors.Is(err, cos.ErrChecksumMismatch) {
+		t.Errorf("unexpected error: want %v, got %v", cos.ErrChecksumMismatch, err)
+	}
+	var checksumErr *cos.ChecksumError
+	if !errors.As(err, &checksumErr) {
+		t.Errorf("unexpected error: want *cos.ChecksumError, got %T", err)
+	} else if checksumErr.Local+1 != checksumErr.Remote {
+		t.Errorf("unexpected checksum: local %v, remote %v", checksumErr.Local, checksumErr.Remote)
+	}
+}
@@ -0,0 +1,137 @@
+/*
+ * Copyright (c) 2025 ivfzhou
+ * tencent-cos-object-api is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package cos
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// 临时密钥到期前多久刷新，不超过有效期的一半。
+const credentialRefreshWindow = 5 * time.Minute
+
+// Credential 访问 COS 的密钥。
+type Credential struct {
+	// SecretId 密钥 ID。
+	SecretId string
+	// SecretKey 密钥。
+	SecretKey string
+	// SessionToken 临时密钥的令牌，使用永久密钥时为空。
+	SessionToken string
+	// Expiration 密钥的过期时间，零值表示永不过期。
+	Expiration time.Time
+}
+
+// CredentialProvider 提供访问 COS 的密钥。客户端会缓存密钥，在过期前重新获取。
+type CredentialProvider interface {
+	// Retrieve 获取密钥。
+	Retrieve(ctx context.Context) (*Credential, error)
+}
+
+// CredentialProviderFunc 使用函数实现 CredentialProvider，如调用 STS 接口获取临时密钥。
+type CredentialProviderFunc func(ctx context.Context) (*Credential, error)
+
+// Retrieve 获取密钥。
+func (f CredentialProviderFunc) Retrieve(ctx context.Context) (*Credential, error) {
+	return f(ctx)
+}
+
+// NewStaticCredentialProvider 使用固定的密钥。sessionToken 为空时是永久密钥。
+func NewStaticCredentialProvider(secretId, secretKey, sessionToken string) CredentialProvider {
+	return CredentialProviderFunc(func(context.Context) (*Credential, error) {
+		return &Credential{SecretId: secretId, SecretKey: secretKey, SessionToken: sessionToken}, nil
+	})
+}
+
+// NewEnvCredentialProvider 从环境变量 TENCENTCLOUD_SECRET_ID、TENCENTCLOUD_SECRET_KEY、TENCENTCLOUD_SESSION_TOKEN 读取密钥。
+func NewEnvCredentialProvider() CredentialProvider {
+	return CredentialProviderFunc(func(context.Context) (*Credential, error) {
+		credential := &Credential{
+			SecretId:     os.Getenv("TENCENTCLOUD_SECRET_ID"),
+			SecretKey:    os.Getenv("TENCENTCLOUD_SECRET_KEY"),
+			SessionToken: os.Getenv("TENCENTCLOUD_SESSION_TOKEN"),
+		}
+		if len(credential.SecretId) <= 0 || len(credential.SecretKey) <= 0 {
+			return nil, errors.New("environment TENCENTCLOUD_SECRET_ID or TENCENTCLOUD_SECRET_KEY is empty")
+		}
+		return credential, nil
+	})
+}
+
+// NewFileCredentialProvider 从 JSON 文件读取密钥，字段与 Credential 相同，Expiration 使用 RFC3339 格式。
+// 密钥过期前会重新读取文件，可由其它程序定期更新文件中的临时密钥。
+func NewFileCredentialProvider(filePath string) CredentialProvider {
+	return CredentialProviderFunc(func(context.Context) (*Credential, error) {
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+		credential := &Credential{}
+		if err = json.Unmarshal(data, credential); err != nil {
+			return nil, fmt.Errorf("parse credential file %s failed: %w", filePath, err)
+		}
+		return credential, nil
+	})
+}
+
+// 缓存密钥，并发获取时只有一个协程刷新。
+type credentialCache struct {
+	provider   CredentialProvider
+	lock       sync.Mutex
+	credential *Credential
+	refreshAt  time.Time
+}
+
+func newCredentialCache(provider CredentialProvider) *credentialCache {
+	return &credentialCache{provider: provider}
+}
+
+// 获取密钥，快过期时重新获取。重新获取失败而缓存的密钥还未过期时，继续使用缓存的密钥。
+func (c *credentialCache) get(ctx context.Context) (Credential, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	now := time.Now()
+	cached := c.credential
+	if cached != nil && (cached.Expiration.IsZero() || now.Before(c.refreshAt)) {
+		return *cached, nil
+	}
+
+	credential, err := c.provider.Retrieve(ctx)
+	if err == nil {
+		switch {
+		case credential == nil:
+			err = errors.New("credential is nil")
+		case len(credential.SecretId) <= 0 || len(credential.SecretKey) <= 0:
+			err = errors.New("credential secretId or secretKey is empty")
+		case !credential.Expiration.IsZero() && !now.Before(credential.Expiration):
+			err = fmt.Errorf("credential has expired at %s", credential.Expiration.Format(time.RFC3339))
+		}
+	}
+	if err != nil {
+		if cached != nil && now.Before(cached.Expiration) {
+			return *cached, nil
+		}
+		return Credential{}, fmt.Errorf("retrieve credential failed: %w", err)
+	}
+
+	// 复制一份，避免提供者修改返回的密钥。
+	copied := *credential
+	c.credential = &copied
+	c.refreshAt = copied.Expiration.Add(-min(credentialRefreshWindow, copied.Expiration.Sub(now)/2))
+	return copied, nil
+}
@@ -0,0 +1,228 @@
+/*
+ * Copyright (c) 2025 ivfzhou
+ * tencent-cos-object-api is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package cos_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	cos "gitee.com/ivfzhou/tencent-cos-object-api"
+)
+
+func TestCredentialProvider(t *testing.T) {
+	ok := func(*http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: NewReader(nil, nil, nil, nil)}, nil
+	}
+
+	t.Run("临时密钥", func(t *testing.T) {
+		fn := func(req *http.Request) (*http.Response, error) {
+			if token := req.Header.Get("x-cos-security-token"); token != "token" {
+				t.Errorf("unexpected token: want token, got %v", token)
+			}
+			auth := req.Header.Get("Authorization")
+			if !CheckAuthorization(auth, req.URL.Path, req.Method, req.Header, req.URL.Query()) {
+				t.Errorf("unexpected auth: got %v", auth)
+			}
+			return ok(req)
+		}
+		client := cos.NewClient(host, "", "", cos.WithHttpClient(MockHttpClient(fn)),
+			cos.WithCredentialProvider(cos.NewStaticCredentialProvider(appKey, appSecret, "token")))
+		if err := client.Ping(context.Background()); err != nil {
+			t.Errorf("unexpected error: want nil, got %v", err)
+		}
+
+		// 签名链接带上令牌。
+		rawUrl := client.PresignUrl(http.MethodGet, "dir/a.txt", nil, nil, cos.AuthExpirationTime)
+		u, err := url.Parse(rawUrl)
+		if err != nil {
+			t.Fatalf("unexpected error: want nil, got %v", err)
+		}
+		query := u.Query()
+		sign := query.Get("sign")
+		query.Del("sign")
+		if query.Get("x-cos-security-token") != "token" {
+			t.Errorf("unexpected url: got %v", rawUrl)
+		}
+		if !CheckAuthorization(sign, u.Path, http.MethodGet, nil, query) {
+			t.Errorf("unexpected sign: got %v", sign)
+		}
+
+		// 自行签名时设置令牌头。
+		header := http.Header{}
+		auth := client.GenerateAuthorization("dir/a.txt", http.MethodGet, nil, header, cos.AuthExpirationTime)
+		if header.Get("x-cos-security-token") != "token" {
+			t.Errorf("unexpected header: got %v", header)
+		}
+		if !CheckAuthorization(auth, "dir/a.txt", http.MethodGet, header, nil) {
+			t.Errorf("unexpected auth: got %v", auth)
+		}
+
+		// 表单带上令牌。
+		form := client.GetPostForm(&cos.PostPolicy{Key: "a.txt"})
+		if form.Fields["x-cos-security-token"] != "token" || form.Fields["q-ak"] != appKey {
+			t.Errorf("unexpected fields: got %v", form.Fields)
+		}
+	})
+
+	t.Run("并发时只获取一次", func(t *testing.T) {
+		var count int32
+		provider := cos.CredentialProviderFunc(func(context.Context) (*cos.Credential, error) {
+			atomic.AddInt32(&count, 1)
+			time.Sleep(10 * time.Millisecond)
+			return &cos.Credential{SecretId: appKey, SecretKey: appSecret, SessionToken: "token",
+				Expiration: time.Now().Add(time.Hour)}, nil
+		})
+		client := cos.NewClient(host, "", "", cos.WithHttpClient(MockHttpClient(ok)),
+			cos.WithCredentialProvider(provider))
+		wg := sync.WaitGroup{}
+		for range 100 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := client.Ping(context.Background()); err != nil {
+					t.Errorf("unexpected error: want nil, got %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+		if count != 1 {
+			t.Errorf("unexpected retrieve count: want 1, got %v", count)
+		}
+	})
+
+	t.Run("过期前刷新", func(t *testing.T) {
+		var count int32
+		provider := cos.CredentialProviderFunc(func(context.Context) (*cos.Credential, error) {
+			n := atomic.AddInt32(&count, 1)
+			if n > 2 {
+				return nil, errors.New("sts unavailable")
+			}
+			return &cos.Credential{SecretId: appKey, SecretKey: appSecret, SessionToken: "token",
+				Expiration: time.Now().Add(200 * time.Millisecond)}, nil
+		})
+		client := cos.NewClient(host, "", "", cos.WithHttpClient(MockHttpClient(ok)),
+			cos.WithCredentialProvider(provider))
+		ctx := context.Background()
+		if err := client.Ping(ctx); err != nil {
+			t.Errorf("unexpected error: want nil, got %v", err)
+		}
+		if err := client.Ping(ctx); err != nil || count != 1 {
+			t.Errorf("unexpected retrieve count: want 1, got %v, %v", count, err)
+		}
+
+		// 过了有效期的一半后重新获取。
+		time.Sleep(120 * time.Millisecond)
+		if err := client.Ping(ctx); err != nil || count != 2 {
+			t.Errorf("unexpected retrieve count: want 2, got %v, %v", count, err)
+		}
+
+		// 刷新失败时，继续使用未过期的密钥。
+		time.Sleep(120 * time.Millisecond)
+		if err := client.Ping(ctx); err != nil || count != 3 {
+			t.Errorf("unexpected retrieve count: want 3, got %v, %v", count, err)
+		}
+
+		// 密钥过期后返回错误。
+		time.Sleep(120 * time.Millisecond)
+		if err := client.Ping(ctx); err == nil {
+			t.Errorf("unexpected error: want non-nil, got nil")
+		}
+	})
+
+	t.Run("匿名访问", func(t *testing.T) {
+		fn := func(req *http.Request) (*http.Response, error) {
+			if v := req.Header.Get("Authorization"); len(v) > 0 {
+				t.Errorf("unexpected auth: want empty, got %v", v)
+			}
+			return ok(req)
+		}
+		client := cos.NewClient(host, "", "", cos.WithHttpClient(MockHttpClient(fn)))
+		if err := client.Ping(context.Background()); err != nil {
+			t.Errorf("unexpected error: want nil, got %v", err)
+		}
+		rc, _, err := client.Download(context.Background(), "a.txt")
+		if err != nil {
+			t.Fatalf("unexpected error: want nil, got %v", err)
+		}
+		if err = rc.Close(); err != nil {
+			t.Errorf("unexpected error: want nil, got %v", err)
+		}
+	})
+
+	t.Run("获取失败", func(t *testing.T) {
+		expectedErr := errors.New("expected error")
+		provider := cos.CredentialProviderFunc(func(context.Context) (*cos.Credential, error) {
+			return nil, expectedErr
+		})
+		fn := func(req *http.Request) (*http.Response, error) {
+			t.Errorf("unexpected request: got %v", req.URL)
+			return ok(req)
+		}
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)),
+			cos.WithCredentialProvider(provider))
+		if err := client.Ping(context.Background()); !errors.Is(err, expectedErr) {
+			t.Errorf("unexpected error: want %v, got %v", expectedErr, err)
+		}
+	})
+
+	t.Run("环境变量", func(t *testing.T) {
+		t.Setenv("TENCENTCLOUD_SECRET_ID", appKey)
+		t.Setenv("TENCENTCLOUD_SECRET_KEY", appSecret)
+		t.Setenv("TENCENTCLOUD_SESSION_TOKEN", "token")
+		credential, err := cos.NewEnvCredentialProvider().Retrieve(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: want nil, got %v", err)
+		}
+		if credential.SecretId != appKey || credential.SecretKey != appSecret || credential.SessionToken != "token" {
+			t.Errorf("unexpected credential: got %v", credential)
+		}
+
+		t.Setenv("TENCENTCLOUD_SECRET_KEY", "")
+		if _, err = cos.NewEnvCredentialProvider().Retrieve(context.Background()); err == nil {
+			t.Errorf("unexpected error: want non-nil, got nil")
+		}
+	})
+
+	t.Run("文件", func(t *testing.T) {
+		filePath := filepath.Join(t.TempDir(), "credential.json")
+		err := os.WriteFile(filePath, []byte(`{"SecretId":"app_key","SecretKey":"app_secret",
+"SessionToken":"token","Expiration":"2099-01-02T03:04:05Z"}`), 0600)
+		if err != nil {
+			t.Fatalf("unexpected error: want nil, got %v", err)
+		}
+		credential, err := cos.NewFileCredentialProvider(filePath).Retrieve(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: want nil, got %v", err)
+		}
+		want := &cos.Credential{SecretId: appKey, SecretKey: appSecret, SessionToken: "token",
+			Expiration: time.Date(2099, 1, 2, 3, 4, 5, 0, time.UTC)}
+		if *credential != *want {
+			t.Errorf("unexpected credential: want %v, got %v", want, credential)
+		}
+
+		if err = os.WriteFile(filePath, []byte("{"), 0600); err != nil {
+			t.Fatalf("unexpected error: want nil, got %v", err)
+		}
+		if _, err = cos.NewFileCredentialProvider(filePath).Retrieve(context.Background()); err == nil {
+			t.Errorf("unexpected error: want non-nil, got nil")
+		}
+	})
+}
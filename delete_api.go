@@ -14,10 +14,29 @@ package cos
 
 import "context"
 
+// FileVersionId 文件的指定版本。
+type FileVersionId struct {
+	// FileId 文件 ID。
+	FileId string
+	// VersionId 版本 ID，为空时删除最新版本。
+	VersionId string
+}
+
 type Deleter interface {
-	// Delete 删除文件。
-	Delete(ctx context.Context, fileId string) error
+	// Delete 删除文件。使用 WithVersionId 永久删除指定版本，否则在开启了版本控制的存储桶中只创建删除标记。
+	Delete(ctx context.Context, fileId string, opts ...option) error
 
 	// Deletes 删除多个文件。
 	Deletes(ctx context.Context, fileIds ...string) (undeleted map[string]error)
+
+	// DeleteVersions 删除多个文件的指定版本。
+	DeleteVersions(ctx context.Context, ids ...FileVersionId) (undeleted map[FileVersionId]error)
+
+	// DeletePrefix 删除目录 prefix 下所有层级的文件，prefix 不能为空。前缀末尾没有 / 时自动补上，与 MovePrefix 一致，
+	// 如 logs 只匹配 logs/ 下的文件，不匹配 logs-old/ 下的文件。
+	// 逐页列举文件，每页最多一千个文件作为一次批量删除请求，使用 NumRoutines 个协程并发删除。
+	// keys 是列举到的文件，使用 WithDryRun 时只列举不删除。undeleted 是删除失败的文件。
+	// 列举文件失败或上下文终止时返回 err。
+	DeletePrefix(ctx context.Context, prefix string, opts ...option) (
+		keys []string, undeleted map[string]error, err error)
 }
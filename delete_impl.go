@@ -20,8 +20,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
 	"net/url"
+	"sync"
+
+	gu "gitee.com/ivfzhou/goroutine-util"
 )
 
 type deleteImpl struct {
@@ -29,18 +33,19 @@ type deleteImpl struct {
 }
 
 // Delete 删除文件。
-func (c *deleteImpl) Delete(ctx context.Context, fileId string) error {
+func (c *deleteImpl) Delete(ctx context.Context, fileId string, opts ...option) error {
 	fileId = suitFileId(fileId)
 	if len(fileId) <= 0 {
 		return errors.New("fileId is invalid")
 	}
+	c = c.with(opts)
 
-	req := c.genReq(http.MethodDelete, fileId, nil, nil, nil)
-	rsp, err := c.sendHttp(ctx, req)
+	req := c.genReq(http.MethodDelete, fileId, c.versionQuery(nil), c.mergeConditionHeader(nil), nil)
+	rsp, err := c.sendHttp(ctx, OperationDeleteObject, req)
 	if err != nil {
 		return err
 	}
-	closeRsp(rsp)
+	c.closeRsp(rsp)
 
 	return nil
 }
@@ -52,94 +57,188 @@ func (c *deleteImpl) Deletes(ctx context.Context, fileIds ...string) (undeleted
 	if len(fileIds) <= 0 {
 		return
 	}
-	cleanedFileIds := make([]string, 0, len(fileIds))
+	ids := make([]FileVersionId, 0, len(fileIds))
 	for _, v := range fileIds {
 		v = suitFileId(v)
 		if len(v) <= 0 {
 			continue
 		}
-		cleanedFileIds = append(cleanedFileIds, v)
+		ids = append(ids, FileVersionId{FileId: v})
 	}
-	if len(cleanedFileIds) <= 0 {
-		return
+
+	// 循环删除文件，每次最多删除一千个。
+	for len(ids) > 0 {
+		n := min(1000, len(ids))
+		for k, v := range c.deleteKeys(ctx, ids[:n]) {
+			undeleted[k.FileId] = v
+		}
+		ids = ids[n:]
+	}
+
+	return
+}
+
+// DeleteVersions 删除多个文件的指定版本。
+func (c *deleteImpl) DeleteVersions(ctx context.Context, ids ...FileVersionId) (
+	undeleted map[FileVersionId]error) {
+
+	// 处理文件 ID。
+	undeleted = make(map[FileVersionId]error, len(ids))
+	cleanedIds := make([]FileVersionId, 0, len(ids))
+	for _, v := range ids {
+		v.FileId = suitFileId(v.FileId)
+		if len(v.FileId) <= 0 {
+			continue
+		}
+		cleanedIds = append(cleanedIds, v)
+	}
+
+	// 循环删除文件，每次最多删除一千个。
+	for len(cleanedIds) > 0 {
+		n := min(1000, len(cleanedIds))
+		maps.Copy(undeleted, c.deleteKeys(ctx, cleanedIds[:n]))
+		cleanedIds = cleanedIds[n:]
+	}
+
+	return
+}
+
+// DeletePrefix 删除前缀下的所有文件。
+func (c *deleteImpl) DeletePrefix(ctx context.Context, prefix string, opts ...option) (
+	keys []string, undeleted map[string]error, err error) {
+
+	prefix = suitDirPrefix(prefix)
+	if len(prefix) <= 0 {
+		return nil, nil, errors.New("prefix is invalid")
+	}
+	c = c.with(opts)
+
+	// 逐页列举文件，每页作为一批并发删除。
+	undeleted = make(map[string]error)
+	lock := sync.Mutex{}
+	run, wait := gu.NewRunner(ctx, c.numRoutines, func(ctx context.Context, ids []FileVersionId) error {
+		failed := c.deleteKeys(ctx, ids)
+		lock.Lock()
+		defer lock.Unlock()
+		for k, v := range failed {
+			undeleted[k.FileId] = v
+		}
+		return nil
+	})
+	for marker := ""; ; {
+		var files []*File
+		files, marker, err = c.listFiles(ctx, prefix, "", marker, 1000)
+		if err != nil {
+			break
+		}
+		ids := make([]FileVersionId, len(files))
+		for i, v := range files {
+			ids[i] = FileVersionId{FileId: v.ID}
+			keys = append(keys, v.ID)
+		}
+		if !c.dryRun && len(ids) > 0 {
+			if err = run(ids, false); err != nil {
+				break
+			}
+		}
+		if len(marker) <= 0 {
+			break
+		}
+	}
+	if err == nil {
+		err = wait(true)
+	} else {
+		_ = wait(false)
 	}
 
+	return keys, undeleted, err
+}
+
+// 使用调用参数覆盖客户端参数。
+func (c *deleteImpl) with(opts []option) *deleteImpl {
+	b := c.baseImpl.with(opts)
+	if b == c.baseImpl {
+		return c
+	}
+	return &deleteImpl{b}
+}
+
+// 使用一次批量删除请求删除文件，文件 ID 不做处理。返回删除失败的文件。
+func (c *deleteImpl) deleteKeys(ctx context.Context, ids []FileVersionId) map[FileVersionId]error {
 	type Object struct {
-		Key string `xml:"Key"`
+		Key       string `xml:"Key"`
+		VersionId string `xml:"VersionId,omitempty"`
 	}
 	type Delete struct {
 		Quiet  bool      `xml:"Quiet"`
 		Object []*Object `xml:",any"`
 	}
-	type Error struct {
-		Code    string `xml:"Code"`
-		Message string `xml:"Message"`
-		Key     string `xml:"Key"`
+	type DeleteError struct {
+		Code      string `xml:"Code"`
+		Message   string `xml:"Message"`
+		Key       string `xml:"Key"`
+		VersionId string `xml:"VersionId"`
 	}
 	type Deleted struct {
 		Key string `xml:"Key"`
 	}
 	type DeleteResult struct {
-		Error   []*Error   `xml:"Error"`
-		Deleted []*Deleted `xml:"Deleted"`
-	}
-
-	// 循环删除文件。
-	for len(cleanedFileIds) > 0 {
-		// 每次最多删除一千个。
-		n := min(1000, len(cleanedFileIds))
-		ids := cleanedFileIds[:n]
-		cleanedFileIds = cleanedFileIds[n:]
-
-		// 组装请求体。
-		query := url.Values{}
-		query.Set("delete", "")
-		reqObj := &Delete{Quiet: true, Object: make([]*Object, len(ids))}
-		for i, v := range ids {
-			reqObj.Object[i] = &Object{Key: v}
-		}
-		reqBody, _ := xml.Marshal(reqObj)
-		header := http.Header{}
-		reqBodySum := md5.Sum(reqBody)
-		header.Set("Content-MD5", base64.StdEncoding.EncodeToString(reqBodySum[:]))
-		req := c.genReq(http.MethodPost, "", query, header, reqBody)
-
-		// 发送 HTTP 请求。
-		rsp, err := c.sendHttp(ctx, req)
-		if err != nil {
-			for _, v := range ids {
-				undeleted[v] = err
-			}
-			continue
-		}
-		rspBody, err := io.ReadAll(rsp.Body)
-		closeRsp(rsp)
-		if err != nil {
-			e := fmt.Errorf("%v %v", err, string(rspBody))
-			for _, v := range ids {
-				undeleted[v] = e
-			}
-			continue
-		}
+		Error   []*DeleteError `xml:"Error"`
+		Deleted []*Deleted     `xml:"Deleted"`
+	}
 
-		// 解析响应体。
-		rspObj := &DeleteResult{}
-		err = xml.Unmarshal(rspBody, rspObj)
-		if err != nil {
-			e := fmt.Errorf("%v %v", err, string(rspBody))
-			for _, v := range ids {
-				undeleted[v] = e
-			}
-			continue
+	undeleted := make(map[FileVersionId]error)
+	failAll := func(err error) map[FileVersionId]error {
+		for _, v := range ids {
+			undeleted[v] = err
 		}
+		return undeleted
+	}
 
-		// 保存删除失败的文件。
-		for _, v := range rspObj.Error {
-			if v.Key != "" {
-				undeleted[v.Key] = fmt.Errorf("code is %s, message is %s", v.Code, v.Message)
+	// 组装请求体。
+	query := url.Values{}
+	query.Set("delete", "")
+	reqObj := &Delete{Quiet: true, Object: make([]*Object, len(ids))}
+	for i, v := range ids {
+		reqObj.Object[i] = &Object{Key: v.FileId, VersionId: v.VersionId}
+	}
+	reqBody, _ := xml.Marshal(reqObj)
+	header := http.Header{}
+	reqBodySum := md5.Sum(reqBody)
+	header.Set("Content-MD5", base64.StdEncoding.EncodeToString(reqBodySum[:]))
+	req := c.genReq(http.MethodPost, "", query, header, reqBody)
+
+	// 发送 HTTP 请求。
+	rsp, err := c.sendHttp(ctx, OperationDeleteMultipleObjects, req)
+	if err != nil {
+		return failAll(err)
+	}
+	rspBody, err := io.ReadAll(rsp.Body)
+	c.closeRsp(rsp)
+	if err != nil {
+		return failAll(fmt.Errorf("%v %v", err, string(rspBody)))
+	}
+
+	// 解析响应体。
+	rspObj := &DeleteResult{}
+	err = xml.Unmarshal(rspBody, rspObj)
+	if err != nil {
+		return failAll(fmt.Errorf("%v %v", err, string(rspBody)))
+	}
+
+	// 保存删除失败的文件。
+	for _, v := range rspObj.Error {
+		if v.Key != "" {
+			undeleted[FileVersionId{FileId: v.Key, VersionId: v.VersionId}] = &Error{
+				StatusCode: rsp.StatusCode,
+				Code:       v.Code,
+				Message:    v.Message,
+				RequestId:  rsp.Header.Get("x-cos-request-id"),
+				Method:     http.MethodPost,
+				Key:        v.Key,
 			}
 		}
 	}
 
-	return
+	return undeleted
 }
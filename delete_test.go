@@ -15,9 +15,11 @@ package cos_test
 import (
 	"context"
 	"encoding/xml"
+	"errors"
 	"io"
 	"math/rand"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"sync/atomic"
@@ -269,3 +271,85 @@ func TestDeletes(t *testing.T) {
 		}
 	})
 }
+
+func TestDeletePrefix(t *testing.T) {
+	t.Run("正常运行", func(t *testing.T) {
+		keys := make([]string, 0, 2500)
+		for i := range 2500 {
+			keys = append(keys, "dir/"+strconv.Itoa(i%7)+"/"+strconv.Itoa(i))
+		}
+		bucket := NewMockBucket(append(keys, "dir/", "dir.txt", "other/1")...)
+		bucket.failDelete["dir/3/10"] = true
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(bucket.Handle)))
+		deleted, undeleted, err := client.DeletePrefix(context.Background(), "/dir/")
+		if err != nil {
+			t.Fatalf("unexpected error: want nil, got %v", err)
+		}
+		if len(deleted) != len(keys)+1 {
+			t.Errorf("unexpected keys: want %v, got %v", len(keys)+1, len(deleted))
+		}
+		if len(undeleted) != 1 || !errors.Is(undeleted["dir/3/10"], cos.ErrAccessDenied) {
+			t.Errorf("unexpected undeleted: got %v", undeleted)
+		}
+		if want := []string{"dir.txt", "dir/3/10", "other/1"}; !slices.Equal(bucket.Keys(), want) {
+			t.Errorf("unexpected left keys: want %v, got %v", want, bucket.Keys())
+		}
+		if n := atomic.LoadInt32(&bucket.deleteCount); n != 3 {
+			t.Errorf("unexpected delete count: want 3, got %v", n)
+		}
+	})
+
+	t.Run("只列举", func(t *testing.T) {
+		bucket := NewMockBucket("dir/a", "dir/b/c", "other/1")
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(bucket.Handle)))
+		deleted, undeleted, err := client.DeletePrefix(context.Background(), "dir/", cos.WithDryRun())
+		if err != nil {
+			t.Fatalf("unexpected error: want nil, got %v", err)
+		}
+		if want := []string{"dir/a", "dir/b/c"}; !slices.Equal(deleted, want) {
+			t.Errorf("unexpected keys: want %v, got %v", want, deleted)
+		}
+		if len(undeleted) != 0 {
+			t.Errorf("unexpected undeleted: want empty, got %v", undeleted)
+		}
+		if len(bucket.Keys()) != 3 || atomic.LoadInt32(&bucket.deleteCount) != 0 {
+			t.Errorf("unexpected delete: got %v", bucket.Keys())
+		}
+	})
+
+	t.Run("列举失败", func(t *testing.T) {
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(
+			func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusForbidden,
+					Body:       NewReader([]byte("<Error><Code>AccessDenied</Code></Error>"), nil, nil, nil),
+				}, nil
+			})))
+		_, _, err := client.DeletePrefix(context.Background(), "dir/")
+		if !errors.Is(err, cos.ErrAccessDenied) {
+			t.Errorf("unexpected error: want %v, got %v", cos.ErrAccessDenied, err)
+		}
+	})
+
+	t.Run("按目录匹配", func(t *testing.T) {
+		bucket := NewMockBucket("logs", "logs/1", "logs/a/2", "logs-old/3", "logs.txt")
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(bucket.Handle)))
+		deleted, undeleted, err := client.DeletePrefix(context.Background(), "logs")
+		if err != nil {
+			t.Fatalf("unexpected error: want nil, got %v", err)
+		}
+		if want := []string{"logs/1", "logs/a/2"}; !slices.Equal(deleted, want) || len(undeleted) != 0 {
+			t.Errorf("unexpected keys: want %v, got %v, %v", want, deleted, undeleted)
+		}
+		if want := []string{"logs", "logs-old/3", "logs.txt"}; !slices.Equal(bucket.Keys(), want) {
+			t.Errorf("unexpected left keys: want %v, got %v", want, bucket.Keys())
+		}
+	})
+
+	t.Run("前缀为空", func(t *testing.T) {
+		_, _, err := cos.NewClient(host, appKey, appSecret).DeletePrefix(context.Background(), "/")
+		if err == nil {
+			t.Errorf("unexpected error: want not nil, got %v", err)
+		}
+	})
+}
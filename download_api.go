@@ -18,23 +18,32 @@ import (
 	"time"
 )
 
+// Downloader 下载文件。opts 可覆盖客户端的参数，只对本次下载生效。
 type Downloader interface {
 	// Download 下载文件。
 	//
 	// 注意：调用方负责关闭 rc。
-	Download(ctx context.Context, fileId string) (rc io.ReadCloser, fileSize int64, err error)
+	Download(ctx context.Context, fileId string, opts ...option) (rc io.ReadCloser, fileSize int64, err error)
 
 	// DownloadToWriter 下载文件。
-	DownloadToWriter(ctx context.Context, fileId string, w io.Writer) error
+	DownloadToWriter(ctx context.Context, fileId string, w io.Writer, opts ...option) error
 
-	// DownloadToWriterWithSize 下载文件。
-	DownloadToWriterWithSize(ctx context.Context, fileId string, contentLength int64, w io.Writer) error
+	// DownloadToWriterWithSize 下载文件。contentLength 是文件大小，不查询文件信息，分片下载时使用范围下载响应中的 CRC64 校验。
+	DownloadToWriterWithSize(ctx context.Context, fileId string, contentLength int64, w io.Writer,
+		opts ...option) error
 
 	// DownloadToDisk 下载文件。
-	DownloadToDisk(ctx context.Context, fileId, filePath string) error
+	DownloadToDisk(ctx context.Context, fileId, filePath string, opts ...option) error
+
+	// ResumableDownloadToDisk 断点续传下载文件。
+	//
+	// 数据先写入 filePath + ".cos-download.tmp" 临时文件，下载进度和文件 ETag 保存在 checkpointPath 检查点文件中，
+	// 为空时使用 filePath + ".cos-download.cp"。下载失败时保留已下载的数据，再次调用只下载缺失的部分。
+	// 文件 ETag 有变化时丢弃已下载的数据。下载成功后将临时文件重命名为 filePath，并删除检查点文件。
+	ResumableDownloadToDisk(ctx context.Context, fileId, filePath, checkpointPath string, opts ...option) error
 
 	// DownloadToWriterAt 下载文件。
-	DownloadToWriterAt(ctx context.Context, fileId string, wa io.WriterAt) error
+	DownloadToWriterAt(ctx context.Context, fileId string, wa io.WriterAt, opts ...option) error
 
 	// GetDownloadUrl 获取文件下载链接。
 	GetDownloadUrl(fileId string, expiration time.Duration) string
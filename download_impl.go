@@ -18,9 +18,10 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
+	"sync"
 	"time"
 
 	gu "gitee.com/ivfzhou/goroutine-util"
@@ -35,53 +36,59 @@ type downloadImpl struct {
 // Download 下载文件。
 //
 // 注意：调用方负责关闭 rc。
-func (c *downloadImpl) Download(ctx context.Context, fileId string) (rc io.ReadCloser, size int64, err error) {
+func (c *downloadImpl) Download(ctx context.Context, fileId string, opts ...option) (rc io.ReadCloser, size int64,
+	err error) {
+
 	fileId = suitFileId(fileId)
 	if len(fileId) <= 0 {
 		return nil, 0, errors.New("fileId is invalid")
 	}
+	c = c.with(opts)
 
 	// 获取文件信息。
-	size, err = c.getFileSize(ctx, fileId)
+	size, crc, err := c.getFileSizeAndCrc64(ctx, fileId)
 	if err != nil {
 		return nil, 0, err
 	}
 
 	// 是否使用分片模式下载。
-	if useMultipart(size) {
-		rc, err = c.multiDownloadToReader(ctx, fileId, size)
+	p := c.newProgress(fileId, size)
+	if c.useMultipart(size) {
+		rc, err = c.multiDownloadToReader(ctx, fileId, size, p, crc)
 		return
 	}
 
-	rc, err = c.download(ctx, fileId)
+	rc, err = c.download(ctx, fileId, p)
 	return
 }
 
 // DownloadToWriter 下载文件。
-func (c *downloadImpl) DownloadToWriter(ctx context.Context, fileId string, w io.Writer) error {
+func (c *downloadImpl) DownloadToWriter(ctx context.Context, fileId string, w io.Writer, opts ...option) error {
 	fileId = suitFileId(fileId)
 	if len(fileId) <= 0 {
 		return errors.New("fileId is invalid")
 	}
+	c = c.with(opts)
 
 	// 获取文件信息。
-	fileSize, err := c.getFileSize(ctx, fileId)
+	fileSize, crc, err := c.getFileSizeAndCrc64(ctx, fileId)
 	if err != nil {
 		return err
 	}
 
 	// 下载。
 	var rc io.ReadCloser
-	if useMultipart(fileSize) {
-		if rc, err = c.multiDownloadToReader(ctx, fileId, fileSize); err != nil {
+	p := c.newProgress(fileId, fileSize)
+	if c.useMultipart(fileSize) {
+		if rc, err = c.multiDownloadToReader(ctx, fileId, fileSize, p, crc); err != nil {
 			return err
 		}
 	} else {
-		if rc, err = c.download(ctx, fileId); err != nil {
+		if rc, err = c.download(ctx, fileId, p); err != nil {
 			return err
 		}
 	}
-	defer closeIO(rc)
+	defer c.closeIO(rc)
 
 	_, err = io.Copy(w, rc)
 	return err
@@ -89,41 +96,44 @@ func (c *downloadImpl) DownloadToWriter(ctx context.Context, fileId string, w io
 
 // DownloadToWriterWithSize 下载文件。
 func (c *downloadImpl) DownloadToWriterWithSize(ctx context.Context, fileId string, contentLength int64,
-	w io.Writer) (err error) {
+	w io.Writer, opts ...option) (err error) {
 
 	fileId = suitFileId(fileId)
 	if len(fileId) <= 0 {
 		return errors.New("fileId is invalid")
 	}
+	c = c.with(opts)
 
-	// 下载。
+	// 下载。没有获取文件信息，分片下载时使用范围下载响应中整个文件的 CRC64 校验。
 	var rc io.ReadCloser
-	if useMultipart(contentLength) {
-		rc, err = c.multiDownloadToReader(ctx, fileId, contentLength)
+	p := c.newProgress(fileId, contentLength)
+	if c.useMultipart(contentLength) {
+		rc, err = c.multiDownloadToReader(ctx, fileId, contentLength, p, "")
 		if err != nil {
 			return err
 		}
 	} else {
-		rc, err = c.download(ctx, fileId)
+		rc, err = c.download(ctx, fileId, p)
 		if err != nil {
 			return err
 		}
 	}
-	defer closeIO(rc)
+	defer c.closeIO(rc)
 
 	_, err = io.Copy(w, rc)
 	return err
 }
 
 // DownloadToDisk 下载文件。
-func (c *downloadImpl) DownloadToDisk(ctx context.Context, fileId, filePath string) (err error) {
+func (c *downloadImpl) DownloadToDisk(ctx context.Context, fileId, filePath string, opts ...option) (err error) {
 	fileId = suitFileId(fileId)
 	if len(fileId) <= 0 {
 		return errors.New("fileId is invalid")
 	}
+	c = c.with(opts)
 
 	// 获取文件信息。
-	fileSize, err := c.getFileSize(ctx, fileId)
+	fileSize, crc, err := c.getFileSizeAndCrc64(ctx, fileId)
 	if err != nil {
 		return err
 	}
@@ -137,106 +147,277 @@ func (c *downloadImpl) DownloadToDisk(ctx context.Context, fileId, filePath stri
 		return err
 	}
 	defer func() {
-		closeIO(fileObj)
+		c.closeIO(fileObj)
 		if err != nil {
-			printError(os.Remove(filePath))
+			c.logError(ctx, "remove incomplete file failed", os.Remove(filePath), "path", filePath)
 		}
 	}()
 
 	// 是否使用分片模式下载。
-	if useMultipart(fileSize) {
-		return c.downloadToWriterAt(ctx, fileId, fileSize, fileObj)
+	p := c.newProgress(fileId, fileSize)
+	if c.useMultipart(fileSize) {
+		return c.downloadToWriterAt(ctx, fileId, fileSize, fileObj, p, crc)
 	}
 
-	rc, err := c.download(ctx, fileId)
+	rc, err := c.download(ctx, fileId, p)
 	if err != nil {
 		return err
 	}
-	defer closeIO(rc)
+	defer c.closeIO(rc)
 	_, err = io.Copy(fileObj, rc)
 	return err
 }
 
 // DownloadToWriterAt 下载文件。
-func (c *downloadImpl) DownloadToWriterAt(ctx context.Context, fileId string, wa io.WriterAt) error {
+func (c *downloadImpl) DownloadToWriterAt(ctx context.Context, fileId string, wa io.WriterAt,
+	opts ...option) error {
+
 	fileId = suitFileId(fileId)
 	if len(fileId) <= 0 {
 		return errors.New("fileId is invalid")
 	}
+	c = c.with(opts)
 
 	// 获取文件信息。
-	fileSize, err := c.getFileSize(ctx, fileId)
+	fileSize, crc, err := c.getFileSizeAndCrc64(ctx, fileId)
 	if err != nil {
 		return err
 	}
 
 	// 是否使用分片模式下载。
-	if useMultipart(fileSize) {
-		return c.downloadToWriterAt(ctx, fileId, fileSize, wa)
+	p := c.newProgress(fileId, fileSize)
+	if c.useMultipart(fileSize) {
+		return c.downloadToWriterAt(ctx, fileId, fileSize, wa, p, crc)
 	}
 
 	// 下载。
-	rc, err := c.download(ctx, fileId)
+	rc, err := c.download(ctx, fileId, p)
 	if err != nil {
 		return err
 	}
-	defer closeIO(rc)
+	defer c.closeIO(rc)
 	_, err = iu.CopyReaderToWriterAt(rc, wa, 0, false)
 	return err
 }
 
+// ResumableDownloadToDisk 断点续传下载文件。
+func (c *downloadImpl) ResumableDownloadToDisk(ctx context.Context, fileId, filePath, checkpointPath string,
+	opts ...option) error {
+
+	fileId = suitFileId(fileId)
+	if len(fileId) <= 0 {
+		return errors.New("fileId is invalid")
+	}
+	c = c.with(opts)
+	if len(checkpointPath) <= 0 {
+		checkpointPath = filePath + downloadCheckpointSuffix
+	}
+	tmpPath := filePath + downloadTmpSuffix
+
+	// 获取文件信息。
+	rsp, err := c.head(ctx, fileId)
+	if err != nil {
+		return err
+	}
+	fileSize := rsp.ContentLength
+	if fileSize <= 0 {
+		fileSize, _ = strconv.ParseInt(rsp.Header.Get("Content-Length"), 10, 64)
+	}
+	etag := rsp.Header.Get("ETag")
+	crc := rsp.Header.Get("x-cos-hash-crc64ecma")
+
+	// 文件较小时整个文件作为一个分片下载。
+	partSize := c.partSize
+	if !c.useMultipart(fileSize) {
+		partSize = max(fileSize, 1)
+	}
+
+	// 读取检查点，文件内容有变化则丢弃已下载的数据。
+	cp := &downloadCheckpoint{}
+	if err = loadCheckpoint(checkpointPath, cp); err != nil || !cp.match(fileId, etag, fileSize, partSize) {
+		c.logError(ctx, "remove temporary file failed", removeIfExists(tmpPath), "path", tmpPath)
+		cp = &downloadCheckpoint{
+			FileId:   fileId,
+			ETag:     etag,
+			FileSize: fileSize,
+			PartSize: partSize,
+			Parts:    make(map[int64]bool),
+		}
+	} else if _, err = os.Stat(tmpPath); err != nil {
+		cp.Parts = make(map[int64]bool)
+	}
+
+	// 开启临时文件流。
+	if err = saveCheckpoint(checkpointPath, cp); err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(filePath), os.ModeDir); err != nil {
+		return err
+	}
+	fileObj, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_RDWR, 0700)
+	if err != nil {
+		return err
+	}
+
+	// 并发下载缺失的分片，下载请求限定 ETag，防止混入文件修改后的数据。
+	header := c.mergeConditionHeader(nil)
+	if len(etag) > 0 {
+		header.Set("If-Match", etag)
+	}
+	type data struct {
+		num, offset, end int64
+	}
+	p := c.newProgress(fileId, fileSize)
+	downloaded := make(map[int64]bool, len(cp.Parts)) // 检查点会被并发修改，分发前复制一份已下载的分片。
+	for num := range cp.Parts {
+		downloaded[num] = true
+		p.skipPart(min(partSize, fileSize-(num-1)*partSize))
+	}
+	crcs := c.newCrc64Parts()
+	lock := sync.Mutex{}
+	run, wait := gu.NewRunner(ctx, c.numRoutines, func(ctx context.Context, t *data) error {
+		pwa := p.writerAt(fileObj)
+		partCrc, _, err := c.downloadPartToWriterAt(ctx, fileId, t.offset, t.end, pwa, false, header)
+		p.writerAtDone(pwa, err)
+		if err != nil {
+			return err
+		}
+		crcs.add(t.num, partCrc, t.end-t.offset+1)
+		lock.Lock()
+		defer lock.Unlock()
+		if err := fileObj.Sync(); err != nil {
+			return err
+		}
+		cp.Parts[t.num] = true
+		return saveCheckpoint(checkpointPath, cp)
+	})
+	for num, offset := int64(1), int64(0); offset < fileSize; num, offset = num+1, offset+partSize {
+		if downloaded[num] {
+			continue
+		}
+		if err = run(&data{num, offset, min(offset+partSize, fileSize) - 1}, false); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = wait(true)
+	}
+	if err != nil {
+		_ = wait(false) // 等待所有协程退出，再关闭临时文件。
+	}
+
+	// 之前下载的分片从临时文件计算校验值。
+	if err == nil && crcs != nil && len(crc) > 0 {
+		for num := range downloaded {
+			offset := (num - 1) * partSize
+			partCrc, e := readCrc64(io.NewSectionReader(fileObj, offset, min(partSize, fileSize-offset)))
+			if e != nil {
+				err = e
+				break
+			}
+			crcs.add(num, partCrc, min(partSize, fileSize-offset))
+		}
+		if err == nil {
+			err = crcs.check(fileId, crc)
+		}
+	}
+	if e := fileObj.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		// 下载期间文件被修改或数据校验不通过，已下载的数据不可再用。
+		if errors.Is(err, ErrPreconditionFailed) || errors.Is(err, ErrChecksumMismatch) {
+			c.logError(ctx, "remove temporary file failed", removeIfExists(tmpPath), "path", tmpPath)
+			c.removeCheckpoint(checkpointPath)
+		}
+		return err
+	}
+
+	// 下载完成，替换目标文件。
+	if err = os.Rename(tmpPath, filePath); err != nil {
+		return err
+	}
+	c.removeCheckpoint(checkpointPath)
+
+	return nil
+}
+
 // GetDownloadUrl 获取文件下载链接。
 func (c *downloadImpl) GetDownloadUrl(fileId string, expiration time.Duration) string {
-	fileId = suitFileId(fileId)
-	signString := c.GenerateAuthorization(fileId, http.MethodGet, nil, nil, expiration)
-	schema := "http"
-	if c.tls {
-		schema = "https"
+	if len(c.downloadDomain) <= 0 {
+		return c.PresignUrl(http.MethodGet, fileId, nil, nil, expiration)
 	}
-	return fmt.Sprintf("%s://%s/%s?sign=%s", schema, c.host, fileId, url.QueryEscape(signString))
+	b := *c.baseImpl
+	b.domain = c.downloadDomain
+	return b.PresignUrl(http.MethodGet, fileId, nil, nil, expiration)
+}
+
+// 使用调用参数覆盖客户端参数。
+func (c *downloadImpl) with(opts []option) *downloadImpl {
+	b := c.baseImpl.with(opts)
+	if b == c.baseImpl {
+		return c
+	}
+	return &downloadImpl{b, c.MultiUploader}
 }
 
 // 下载文件，并从读取流中读出。
-func (c *downloadImpl) download(ctx context.Context, fileId string) (io.ReadCloser, error) {
-	req := c.genReq(http.MethodGet, fileId, nil, nil, nil)
-	rsp, err := c.sendHttp(ctx, req)
+func (c *downloadImpl) download(ctx context.Context, fileId string, p *progress) (io.ReadCloser, error) {
+	header := c.mergeConditionHeader(c.mergeEncryptionHeader(nil))
+	req := c.genReq(http.MethodGet, fileId, c.versionQuery(nil), header, nil)
+	rsp, err := c.sendHttp(ctx, OperationGetObject, req)
 	if err != nil {
+		p.partDone(err, 0)
 		return nil, err
 	}
-	return rsp.Body, nil
+	return c.crc64ReadCloser(p.readCloser(rsp.Body), fileId, rsp.Header.Get("x-cos-hash-crc64ecma")), nil
 }
 
-// 下载文件到写入流。
+// 下载文件到写入流。crc 是服务端的 CRC64，为空时不校验。
 func (c *downloadImpl) downloadToWriterAt(ctx context.Context, fileId string, fileSize int64,
-	wa io.WriterAt) (err error) {
+	wa io.WriterAt, p *progress, crc string) (err error) {
 
 	type data struct {
 		offset, end int64
 	}
-	run, wait := gu.NewRunner(ctx, NumRoutines, func(ctx context.Context, t *data) error {
-		return c.downloadPartToWriterAt(ctx, fileId, t.offset, t.end, wa, false)
+	header := c.mergeConditionHeader(nil)
+	crcs := c.newCrc64Parts()
+	run, wait := gu.NewRunner(ctx, c.numRoutines, func(ctx context.Context, t *data) error {
+		pwa := p.writerAt(wa)
+		partCrc, _, err := c.downloadPartToWriterAt(ctx, fileId, t.offset, t.end, pwa, false, header)
+		p.writerAtDone(pwa, err)
+		if err == nil {
+			crcs.add(t.offset, partCrc, t.end-t.offset+1)
+		}
+		return err
 	})
 
 	// 并发下载。
-	partSize := getPartSize()
+	partSize := c.partSize
 	for offset, end, next := int64(0), partSize-1, true; next; {
 		if end >= fileSize-1 {
 			end = fileSize - 1
 			next = false
 		}
 		if err = run(&data{offset, end}, false); err != nil {
-			return err
+			break
 		}
 		offset += partSize
 		end = offset + partSize - 1
 	}
-
-	return wait(true)
+	if err == nil {
+		err = wait(true)
+	}
+	if err != nil {
+		_ = wait(false) // 等待所有协程退出，返回后不再写入、回调进度。
+		return err
+	}
+	return crcs.check(fileId, crc)
 }
 
-// 下载文件，并从读取流中读出。
-func (c *downloadImpl) multiDownloadToReader(ctx context.Context, fileId string, fileSize int64) (
-	io.ReadCloser, error) {
+// 下载文件，并从读取流中读出。crc 是服务端的 CRC64，为空时使用范围下载响应中整个文件的 CRC64 校验。
+func (c *downloadImpl) multiDownloadToReader(ctx context.Context, fileId string, fileSize int64, p *progress,
+	crc string) (io.ReadCloser, error) {
 
 	var (
 		wc iu.WriteAtCloser
@@ -253,13 +434,28 @@ func (c *downloadImpl) multiDownloadToReader(ctx context.Context, fileId string,
 	type data struct {
 		offset, end int64
 	}
-	run, wait := gu.NewRunner(ctx, NumRoutines, func(ctx context.Context, t *data) error {
-		return c.downloadPartToWriterAt(ctx, fileId, t.offset, t.end, wc, c.nonUseDisk)
+	header := c.mergeConditionHeader(nil)
+	crcs := c.newCrc64Parts()
+	lock := sync.Mutex{}
+	run, wait := gu.NewRunner(ctx, c.numRoutines, func(ctx context.Context, t *data) error {
+		pwa := p.writerAt(wc)
+		partCrc, remote, err := c.downloadPartToWriterAt(ctx, fileId, t.offset, t.end, pwa, c.nonUseDisk, header)
+		p.writerAtDone(pwa, err)
+		if err != nil {
+			return err
+		}
+		crcs.add(t.offset, partCrc, t.end-t.offset+1)
+		lock.Lock()
+		defer lock.Unlock()
+		if len(crc) <= 0 {
+			crc = remote
+		}
+		return nil
 	})
 
 	// 并发下载数据。
 	go func() {
-		partSize := getPartSize()
+		partSize := c.partSize
 		for offset, end, next := int64(0), partSize-1, true; next; {
 			if end > fileSize-1 {
 				end = fileSize - 1
@@ -267,39 +463,73 @@ func (c *downloadImpl) multiDownloadToReader(ctx context.Context, fileId string,
 			}
 
 			if err := run(&data{offset, end}, false); err != nil {
-				printError(wc.CloseByError(err))
+				_ = wait(false) // 等待所有协程退出，再关闭写入流。
+				c.logError(ctx, "close writer failed", wc.CloseByError(err), "key", fileId)
 				return
 			}
 			offset += partSize
 			end = offset + partSize - 1
 		}
-		printError(wc.CloseByError(wait(true)))
+		err := wait(true)
+		if err != nil {
+			_ = wait(false)
+		} else {
+			lock.Lock()
+			err = crcs.check(fileId, crc)
+			lock.Unlock()
+		}
+		c.logError(ctx, "close writer failed", wc.CloseByError(err), "key", fileId)
 	}()
 
 	return rc, nil
 }
 
-// 下载分片字节数据到写入流。
+// 下载分片字节数据到写入流，返回分片的 CRC64 和响应中整个文件的 CRC64。
+// 读取响应体失败时，按重试策略从中断处继续下载。header 是额外的请求头，可为 nil。
 func (c *downloadImpl) downloadPartToWriterAt(ctx context.Context, fileId string, offset, end int64,
-	wa io.WriterAt, nonBuffer bool) error {
+	wa io.WriterAt, nonBuffer bool, header http.Header) (uint64, string, error) {
 
-	header := http.Header{}
-	header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, end))
-	req := c.genReq(http.MethodGet, fileId, nil, header, nil)
+	var (
+		crc    uint64
+		remote string
+	)
+	for attempt := 1; ; attempt++ {
+		reqHeader := header.Clone()
+		if reqHeader == nil {
+			reqHeader = http.Header{}
+		}
+		reqHeader.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, end))
+		req := c.genReq(http.MethodGet, fileId, c.versionQuery(nil), c.mergeEncryptionHeader(reqHeader), nil)
 
-	rsp, err := c.sendHttp(ctx, req)
-	defer closeRsp(rsp)
-	if err != nil {
-		return err
-	}
-	n, err := iu.CopyReaderToWriterAt(rsp.Body, wa, offset, nonBuffer)
-	if err != nil {
-		return err
-	}
-	if n != end-offset+1 {
-		return fmt.Errorf("part size not match, actual is %v, expected is %v, offset is %v, end is %v",
-			n, end-offset+1, offset, end)
-	}
+		rsp, err := c.sendHttp(ctx, OperationGetObject, req)
+		if err != nil {
+			return 0, "", err
+		}
+		remote = rsp.Header.Get("x-cos-hash-crc64ecma")
+		r := &crc64Reader{r: rsp.Body, crc: crc}
+		n, err := iu.CopyReaderToWriterAt(r, wa, offset, nonBuffer)
+		c.closeRsp(rsp)
+		if err == nil {
+			if n != end-offset+1 {
+				return 0, "", fmt.Errorf("part size not match, actual is %v, expected is %v, offset is %v, end is %v",
+					n, end-offset+1, offset, end)
+			}
+			return r.crc, remote, nil
+		}
 
-	return nil
+		// 读出但没有写入的数据无法接续计算校验值，不再重试。
+		if r.n != n || !c.retryPolicy.shouldRetry(ctx, attempt, err) {
+			return 0, "", err
+		}
+
+		// 已写入的数据不再重复下载。
+		offset += n
+		crc = r.crc
+		if offset > end {
+			return crc, remote, nil
+		}
+		if err = sleepWithContext(ctx, c.retryPolicy.delay(attempt, err)); err != nil {
+			return 0, "", err
+		}
+	}
 }
@@ -1774,3 +1774,96 @@ func TestDownloadToWriterAt(t *testing.T) {
 		}
 	})
 }
+
+func TestResumableDownloadToDisk(t *testing.T) {
+	for _, etagChanged := range []bool{false, true} {
+		name := "断点续传"
+		if etagChanged {
+			name = "文件已修改"
+		}
+		t.Run(name, func(t *testing.T) {
+			for range 5 {
+				partSize := int64(1024 * 1024)
+				data := MakeBytesWithSize(int(partSize)*(rand.Intn(4)+3) + rand.Intn(1024) + 1)
+				partCount := (int64(len(data)) + partSize - 1) / partSize
+				etag := "expected etag"
+				filePath := filepath.Join(t.TempDir(), "ivfzhou_test_file")
+				checkpointPath := filePath + ".cp"
+				occurErrPartNum := rand.Int63n(partCount) + 1
+				failed := true
+				downloaded := sync.Map{}
+				fn := func(req *http.Request) (*http.Response, error) {
+					header := http.Header{}
+					header.Set("ETag", etag)
+					if req.Method == http.MethodHead {
+						return &http.Response{
+							StatusCode:    http.StatusOK,
+							Header:        header,
+							ContentLength: int64(len(data)),
+							Body:          NewReader(nil, nil, nil, nil),
+						}, nil
+					}
+					if v := req.Header.Get("If-Match"); v != etag {
+						t.Errorf("unexpected if-match: want %v, got %v", etag, v)
+					}
+					var begin, end int64
+					if _, err := fmt.Sscanf(req.Header.Get("Range"), "bytes=%d-%d", &begin, &end); err != nil {
+						t.Errorf("unexpected error: want nil, got %v", err)
+					}
+					num := begin/partSize + 1
+					if failed && num == occurErrPartNum {
+						return &http.Response{
+							StatusCode: http.StatusInternalServerError,
+							Body:       NewReader(nil, nil, nil, nil),
+						}, nil
+					}
+					if _, loaded := downloaded.LoadOrStore(num, true); loaded && !etagChanged {
+						t.Errorf("unexpected download: part %v already downloaded", num)
+					}
+					return &http.Response{
+						StatusCode: http.StatusPartialContent,
+						Header:     header,
+						Body:       NewReader(data[begin:end+1], nil, nil, nil),
+					}, nil
+				}
+				client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)),
+					cos.WithPartSize(partSize), cos.WithMultiThreshold(2))
+				err := client.ResumableDownloadToDisk(context.Background(), "ivfzhou_test_file", filePath,
+					checkpointPath)
+				if err == nil {
+					t.Errorf("unexpected error: want not nil, got %v", err)
+				}
+				if _, err = os.Stat(filePath); !os.IsNotExist(err) {
+					t.Errorf("unexpected error: want not exist, got %v", err)
+				}
+				if _, err = os.Stat(checkpointPath); err != nil {
+					t.Errorf("unexpected error: want nil, got %v", err)
+				}
+				failed = false
+				if etagChanged {
+					etag = "changed etag"
+					data = MakeBytesWithSize(len(data))
+				}
+				err = client.ResumableDownloadToDisk(context.Background(), "ivfzhou_test_file", filePath,
+					checkpointPath)
+				if err != nil {
+					t.Errorf("unexpected error: want nil, got %v", err)
+				}
+				bs, err := os.ReadFile(filePath)
+				if err != nil {
+					t.Errorf("unexpected error: want nil, got %v", err)
+				}
+				if !bytes.Equal(bs, data) {
+					t.Errorf("unexpected result: want %v, got %v", len(data), len(bs))
+				}
+				if _, err = os.Stat(checkpointPath); !os.IsNotExist(err) {
+					t.Errorf("unexpected error: want not exist, got %v", err)
+				}
+				matches, _ := filepath.Glob(filePath + ".*")
+				if len(matches) > 0 {
+					t.Errorf("unexpected files: want none, got %v", matches)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright (c) 2025 ivfzhou
+ * tencent-cos-object-api is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package cos
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"errors"
+	"net/http"
+)
+
+// EncryptionMode 服务端加密方式。
+type EncryptionMode string
+
+const (
+	// EncryptionNone 不加密。
+	EncryptionNone EncryptionMode = ""
+	// EncryptionSSECOS 使用 COS 托管密钥加密。
+	EncryptionSSECOS EncryptionMode = "SSE-COS"
+	// EncryptionSSEKMS 使用 KMS 托管密钥加密。
+	EncryptionSSEKMS EncryptionMode = "SSE-KMS"
+	// EncryptionSSEC 使用客户提供的密钥加密。
+	EncryptionSSEC EncryptionMode = "SSE-C"
+)
+
+// 服务端加密参数。
+type serverSideEncryption struct {
+	mode       EncryptionMode
+	kmsKeyId   string
+	kmsContext string
+	customKey  []byte
+}
+
+// 在 header 中设置加密的 HTTP 头。write 表示是否是创建文件的请求，创建文件时才需要设置加密方式，
+// 使用客户提供的密钥时，读取文件和上传分片也需要携带密钥。
+func (e *serverSideEncryption) setHeader(header http.Header, write bool) {
+	if e == nil {
+		return
+	}
+	switch e.mode {
+	case EncryptionSSECOS:
+		if write {
+			header.Set("x-cos-server-side-encryption", "AES256")
+		}
+	case EncryptionSSEKMS:
+		if write {
+			header.Set("x-cos-server-side-encryption", "cos/kms")
+			if len(e.kmsKeyId) > 0 {
+				header.Set("x-cos-server-side-encryption-cos-kms-key-id", e.kmsKeyId)
+			}
+			if len(e.kmsContext) > 0 {
+				header.Set("x-cos-server-side-encryption-context",
+					base64.StdEncoding.EncodeToString([]byte(e.kmsContext)))
+			}
+		}
+	case EncryptionSSEC:
+		sum := md5.Sum(e.customKey)
+		header.Set("x-cos-server-side-encryption-customer-algorithm", "AES256")
+		header.Set("x-cos-server-side-encryption-customer-key", base64.StdEncoding.EncodeToString(e.customKey))
+		header.Set("x-cos-server-side-encryption-customer-key-MD5", base64.StdEncoding.EncodeToString(sum[:]))
+	}
+}
+
+// 复制文件时在 header 中设置解密源文件的 HTTP 头。使用客户提供的密钥时，源文件也使用相同的密钥。
+func (e *serverSideEncryption) setCopySourceHeader(header http.Header) {
+	if e == nil || e.mode != EncryptionSSEC {
+		return
+	}
+	sum := md5.Sum(e.customKey)
+	header.Set("x-cos-copy-source-server-side-encryption-customer-algorithm", "AES256")
+	header.Set("x-cos-copy-source-server-side-encryption-customer-key", base64.StdEncoding.EncodeToString(e.customKey))
+	header.Set("x-cos-copy-source-server-side-encryption-customer-key-MD5", base64.StdEncoding.EncodeToString(sum[:]))
+}
+
+// 检查携带客户密钥的请求，密钥必须是 32 字节，且只能通过 HTTPS 发送。
+func (e *serverSideEncryption) check(req *http.Request) error {
+	if e == nil || e.mode != EncryptionSSEC {
+		return nil
+	}
+	if len(req.Header.Get("x-cos-server-side-encryption-customer-key")) <= 0 &&
+		len(req.Header.Get("x-cos-copy-source-server-side-encryption-customer-key")) <= 0 {
+		return nil
+	}
+	if len(e.customKey) != 32 {
+		return errors.New("SSE-C key must be 32 bytes")
+	}
+	if req.URL.Scheme != "https" {
+		return errors.New("SSE-C requires https")
+	}
+	return nil
+}
+
+// 从响应头中解析加密方式。
+func parseEncryption(header http.Header) (mode EncryptionMode, kmsKeyId string) {
+	switch {
+	case len(header.Get("x-cos-server-side-encryption-customer-algorithm")) > 0:
+		return EncryptionSSEC, ""
+	case header.Get("x-cos-server-side-encryption") == "cos/kms":
+		return EncryptionSSEKMS, header.Get("x-cos-server-side-encryption-cos-kms-key-id")
+	case len(header.Get("x-cos-server-side-encryption")) > 0:
+		return EncryptionSSECOS, ""
+	}
+	return EncryptionNone, ""
+}
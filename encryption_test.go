@@ -0,0 +1,289 @@
+/*
+ * Copyright (c) 2025 ivfzhou
+ * tencent-cos-object-api is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package cos_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	cos "gitee.com/ivfzhou/tencent-cos-object-api"
+)
+
+func TestEncryption(t *testing.T) {
+	partSize := int64(1024 * 1024)
+	initiateRsp := []byte("<InitiateMultipartUploadResult><UploadId>upload id</UploadId></InitiateMultipartUploadResult>")
+	listPartsRsp := []byte("<ListPartsResult></ListPartsResult>")
+
+	t.Run("上传加密", func(t *testing.T) {
+		for i := range 2 {
+			opt := cos.WithSSECOS()
+			wantHeader := map[string]string{"x-cos-server-side-encryption": "AES256"}
+			if i == 1 {
+				opt = cos.WithSSEKMS("key id", `{"a":"b"}`)
+				wantHeader = map[string]string{
+					"x-cos-server-side-encryption":                "cos/kms",
+					"x-cos-server-side-encryption-cos-kms-key-id": "key id",
+					"x-cos-server-side-encryption-context":        base64.StdEncoding.EncodeToString([]byte(`{"a":"b"}`)),
+				}
+			}
+			for _, much := range []bool{false, true} {
+				data := MakeBytesWithSize(rand.Intn(1024) + 1)
+				if much {
+					data = MakeBytesWithSize(int(partSize)*3 + rand.Intn(1024) + 1)
+				}
+				var createCount int32
+				atomic.StoreInt32(&CloseCount, 0)
+				fn := func(req *http.Request) (*http.Response, error) {
+					auth := req.Header.Get("Authorization")
+					if !CheckAuthorization(auth, req.URL.Path, req.Method, req.Header, req.URL.Query()) {
+						t.Errorf("unexpected auth: got %v", auth)
+					}
+					initiate := req.Method == http.MethodPost && req.URL.Query().Has("uploads")
+					create := initiate || (req.Method == http.MethodPut && !req.URL.Query().Has("partNumber"))
+					for k, want := range wantHeader {
+						if got := req.Header.Get(k); create && got != want {
+							t.Errorf("unexpected header %v: want %v, got %v", k, want, got)
+						} else if !create && len(got) > 0 {
+							t.Errorf("unexpected header %v: want empty, got %v", k, got)
+						}
+					}
+					if create {
+						atomic.AddInt32(&createCount, 1)
+					}
+					if initiate {
+						return &http.Response{StatusCode: http.StatusOK, Body: NewReader(initiateRsp, nil, nil, nil)}, nil
+					}
+					if req.Method == http.MethodGet {
+						return &http.Response{StatusCode: http.StatusOK, Body: NewReader(listPartsRsp, nil, nil, nil)}, nil
+					}
+					if req.Method == http.MethodPut {
+						if _, err := io.Copy(io.Discard, req.Body); err != nil {
+							t.Errorf("unexpected error: want nil, got %v", err)
+						}
+					}
+					return &http.Response{StatusCode: http.StatusOK, Body: NewReader(nil, nil, nil, nil)}, nil
+				}
+				client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)),
+					cos.WithPartSize(partSize), cos.WithMultiThreshold(2))
+				if err := client.Upload(context.Background(), "/ivfzhou_test_file", data, opt); err != nil {
+					t.Errorf("unexpected error: want nil, got %v", err)
+				}
+				if n := atomic.LoadInt32(&createCount); n != 1 {
+					t.Errorf("unexpected create count: want 1, got %v", n)
+				}
+				if closeCount := atomic.LoadInt32(&CloseCount); closeCount != 0 {
+					t.Errorf("unexpected close count: want 0, got %v", closeCount)
+				}
+			}
+		}
+	})
+
+	t.Run("客户提供密钥", func(t *testing.T) {
+		key := MakeBytesWithSize(32)
+		sum := md5.Sum(key)
+		wantHeader := map[string]string{
+			"x-cos-server-side-encryption-customer-algorithm": "AES256",
+			"x-cos-server-side-encryption-customer-key":       base64.StdEncoding.EncodeToString(key),
+			"x-cos-server-side-encryption-customer-key-MD5":   base64.StdEncoding.EncodeToString(sum[:]),
+		}
+		for _, much := range []bool{false, true} {
+			data := MakeBytesWithSize(rand.Intn(1024) + 1)
+			if much {
+				data = MakeBytesWithSize(int(partSize)*3 + rand.Intn(1024) + 1)
+			}
+			var reqCount int32
+			atomic.StoreInt32(&CloseCount, 0)
+			fn := func(req *http.Request) (*http.Response, error) {
+				auth := req.Header.Get("Authorization")
+				if !CheckAuthorization(auth, req.URL.Path, req.Method, req.Header, req.URL.Query()) {
+					t.Errorf("unexpected auth: got %v", auth)
+				}
+				query := req.URL.Query()
+				listParts := req.Method == http.MethodGet && query.Has("uploadId")
+				complete := req.Method == http.MethodPost && query.Has("uploadId")
+				if !listParts && !complete && req.Method != http.MethodDelete {
+					atomic.AddInt32(&reqCount, 1)
+					for k, want := range wantHeader {
+						if got := req.Header.Get(k); got != want {
+							t.Errorf("unexpected header %v %v: want %v, got %v", req.Method, k, want, got)
+						}
+					}
+				}
+				switch {
+				case req.Method == http.MethodPost && query.Has("uploads"):
+					return &http.Response{StatusCode: http.StatusOK, Body: NewReader(initiateRsp, nil, nil, nil)}, nil
+				case listParts:
+					return &http.Response{StatusCode: http.StatusOK, Body: NewReader(listPartsRsp, nil, nil, nil)}, nil
+				case req.Method == http.MethodPut:
+					if _, err := io.Copy(io.Discard, req.Body); err != nil {
+						t.Errorf("unexpected error: want nil, got %v", err)
+					}
+				case req.Method == http.MethodHead:
+					header := http.Header{}
+					header.Set("Content-Length", fmt.Sprintf("%d", len(data)))
+					header.Set("x-cos-server-side-encryption-customer-algorithm", "AES256")
+					return &http.Response{StatusCode: http.StatusOK, Header: header, Body: NewReader(nil, nil, nil, nil)}, nil
+				case req.Method == http.MethodGet:
+					begin, end := int64(0), int64(len(data)-1)
+					if v := req.Header.Get("Range"); len(v) > 0 {
+						if _, err := fmt.Sscanf(v, "bytes=%d-%d", &begin, &end); err != nil {
+							t.Errorf("unexpected error: want nil, got %v", err)
+						}
+					}
+					return &http.Response{StatusCode: http.StatusOK, Body: NewReader(data[begin:end+1], nil, nil, nil)}, nil
+				}
+				return &http.Response{StatusCode: http.StatusOK, Body: NewReader(nil, nil, nil, nil)}, nil
+			}
+			client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)),
+				cos.WithPartSize(partSize), cos.WithMultiThreshold(2), cos.WithHttps())
+			opt := cos.WithSSEC(key)
+			if err := client.Upload(context.Background(), "/ivfzhou_test_file", data, opt); err != nil {
+				t.Errorf("unexpected error: want nil, got %v", err)
+			}
+			buf := &bytes.Buffer{}
+			if err := client.DownloadToWriter(context.Background(), "/ivfzhou_test_file", buf, opt); err != nil {
+				t.Errorf("unexpected error: want nil, got %v", err)
+			}
+			if !bytes.Equal(buf.Bytes(), data) {
+				t.Errorf("unexpected result: want %v, got %v", len(data), buf.Len())
+			}
+			info, err := client.Info(context.Background(), "/ivfzhou_test_file", opt)
+			if err != nil {
+				t.Errorf("unexpected error: want nil, got %v", err)
+			}
+			if info.Encryption != cos.EncryptionSSEC {
+				t.Errorf("unexpected encryption: want %v, got %v", cos.EncryptionSSEC, info.Encryption)
+			}
+			if n := atomic.LoadInt32(&reqCount); n < 4 {
+				t.Errorf("unexpected request count: want >= 4, got %v", n)
+			}
+			if closeCount := atomic.LoadInt32(&CloseCount); closeCount != 0 {
+				t.Errorf("unexpected close count: want 0, got %v", closeCount)
+			}
+		}
+	})
+
+	t.Run("复制客户密钥加密的文件", func(t *testing.T) {
+		key := MakeBytesWithSize(32)
+		sum := md5.Sum(key)
+		wantHeader := map[string]string{
+			"x-cos-copy-source-server-side-encryption-customer-algorithm": "AES256",
+			"x-cos-copy-source-server-side-encryption-customer-key":       base64.StdEncoding.EncodeToString(key),
+			"x-cos-copy-source-server-side-encryption-customer-key-MD5":   base64.StdEncoding.EncodeToString(sum[:]),
+			"x-cos-server-side-encryption-customer-key":                   base64.StdEncoding.EncodeToString(key),
+		}
+		var copyCount int32
+		fn := func(req *http.Request) (*http.Response, error) {
+			if req.Method == http.MethodHead {
+				header := http.Header{}
+				header.Set("Content-Length", "3")
+				return &http.Response{StatusCode: http.StatusOK, Header: header, Body: NewReader(nil, nil, nil, nil)}, nil
+			}
+			atomic.AddInt32(&copyCount, 1)
+			for k, want := range wantHeader {
+				if got := req.Header.Get(k); got != want {
+					t.Errorf("unexpected header %v: want %v, got %v", k, want, got)
+				}
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       NewReader([]byte("<CopyObjectResult></CopyObjectResult>"), nil, nil, nil),
+			}, nil
+		}
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)), cos.WithHttps(),
+			cos.WithSSEC(key))
+		if err := client.Copy(context.Background(), "/src", "/dst"); err != nil {
+			t.Errorf("unexpected error: want nil, got %v", err)
+		}
+		if n := atomic.LoadInt32(&copyCount); n != 1 {
+			t.Errorf("unexpected copy count: want 1, got %v", n)
+		}
+	})
+
+	t.Run("客户密钥校验", func(t *testing.T) {
+		var reqCount int32
+		fn := func(req *http.Request) (*http.Response, error) {
+			atomic.AddInt32(&reqCount, 1)
+			return &http.Response{StatusCode: http.StatusOK, Body: NewReader(nil, nil, nil, nil)}, nil
+		}
+		cases := []struct {
+			key   []byte
+			https bool
+		}{
+			{MakeBytesWithSize(16), true},
+			{MakeBytesWithSize(32), false},
+		}
+		for _, v := range cases {
+			client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)),
+				cos.WithSSEC(v.key))
+			if v.https {
+				client = cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)),
+					cos.WithSSEC(v.key), cos.WithHttps())
+			}
+			if err := client.Upload(context.Background(), "/ivfzhou_test_file", []byte("a")); err == nil {
+				t.Errorf("unexpected error: want not nil, got %v", err)
+			}
+		}
+		if n := atomic.LoadInt32(&reqCount); n != 0 {
+			t.Errorf("unexpected request count: want 0, got %v", n)
+		}
+
+		// 不携带密钥的请求不受影响。
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)),
+			cos.WithSSEC(MakeBytesWithSize(32)))
+		if err := client.Delete(context.Background(), "/ivfzhou_test_file"); err != nil {
+			t.Errorf("unexpected error: want nil, got %v", err)
+		}
+	})
+
+	t.Run("文件信息", func(t *testing.T) {
+		cases := []struct {
+			header   map[string]string
+			want     cos.EncryptionMode
+			kmsKeyId string
+		}{
+			{nil, cos.EncryptionNone, ""},
+			{map[string]string{"x-cos-server-side-encryption": "AES256"}, cos.EncryptionSSECOS, ""},
+			{map[string]string{"x-cos-server-side-encryption": "cos/kms",
+				"x-cos-server-side-encryption-cos-kms-key-id": "key id"}, cos.EncryptionSSEKMS, "key id"},
+		}
+		for _, v := range cases {
+			fn := func(req *http.Request) (*http.Response, error) {
+				header := http.Header{}
+				for k, h := range v.header {
+					header.Set(k, h)
+				}
+				return &http.Response{StatusCode: http.StatusOK, Header: header, Body: NewReader(nil, nil, nil, nil)}, nil
+			}
+			info, err := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn))).
+				Info(context.Background(), "/ivfzhou_test_file")
+			if err != nil {
+				t.Fatalf("unexpected error: want nil, got %v", err)
+			}
+			if info.Encryption != v.want {
+				t.Errorf("unexpected encryption: want %v, got %v", v.want, info.Encryption)
+			}
+			if info.KMSKeyId != v.kmsKeyId {
+				t.Errorf("unexpected kms key id: want %v, got %v", v.kmsKeyId, info.KMSKeyId)
+			}
+		}
+	})
+}
@@ -0,0 +1,63 @@
+/*
+ * Copyright (c) 2025 ivfzhou
+ * tencent-cos-object-api is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package cos
+
+import (
+	"net/http"
+	"strings"
+)
+
+// 存储桶默认域名的后缀。
+const defaultDomainSuffix = ".myqcloud.com"
+
+// BucketHost 存储桶的默认域名 <bucket>-<appId>.cos.<region>.myqcloud.com。bucket 已带有 -<appId> 时不再追加。
+func BucketHost(bucket, appId, region string) string {
+	if len(appId) > 0 && !strings.HasSuffix(bucket, "-"+appId) {
+		bucket += "-" + appId
+	}
+	return bucket + ".cos." + region + defaultDomainSuffix
+}
+
+// 请求使用的域名。
+func (c *baseImpl) requestHost() string {
+	switch {
+	case len(c.domain) > 0:
+		return c.domain
+	case c.accelerate:
+		return accelerateHost(c.host)
+	}
+	return c.host
+}
+
+// 请求的路径，设置了 basePath 时加上前缀。
+func (c *baseImpl) objectPath(fileId string) string {
+	fileId = strings.TrimLeft(fileId, "/")
+	if len(c.basePath) <= 0 {
+		return "/" + fileId
+	}
+	return "/" + c.basePath + "/" + fileId
+}
+
+// 从请求路径中解析出对象键，去掉 basePath 前缀。
+func (c *baseImpl) objectKey(req *http.Request) string {
+	return strings.TrimPrefix(req.URL.Path, c.objectPath(""))
+}
+
+// 将存储桶的默认域名转换为全球加速域名，不是默认域名时原样返回。
+func accelerateHost(host string) string {
+	bucket, _, ok := strings.Cut(host, ".cos.")
+	if !ok || !strings.HasSuffix(host, defaultDomainSuffix) {
+		return host
+	}
+	return bucket + ".cos.accelerate" + defaultDomainSuffix
+}
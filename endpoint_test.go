@@ -0,0 +1,154 @@
+/*
+ * Copyright (c) 2025 ivfzhou
+ * tencent-cos-object-api is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package cos_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	cos "gitee.com/ivfzhou/tencent-cos-object-api"
+)
+
+func TestEndpoint(t *testing.T) {
+	const bucketHost = "examplebucket-1250000000.cos.ap-guangzhou.myqcloud.com"
+	checkHost := func(t *testing.T, wantHost, wantPath string) func(*http.Request) (*http.Response, error) {
+		return func(req *http.Request) (*http.Response, error) {
+			if req.Host != wantHost || req.URL.Host != wantHost || req.Header.Get("Host") != wantHost {
+				t.Errorf("unexpected host: want %v, got %v", wantHost, req.Host)
+			}
+			if req.URL.Path != wantPath {
+				t.Errorf("unexpected path: want %v, got %v", wantPath, req.URL.Path)
+			}
+			auth := req.Header.Get("Authorization")
+			if !CheckAuthorization(auth, req.URL.Path, req.Method, req.Header, req.URL.Query()) {
+				t.Errorf("unexpected auth: got %v", auth)
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: NewReader(nil, nil, nil, nil)}, nil
+		}
+	}
+	urlHost := func(rawUrl string) (string, string) {
+		u, err := url.Parse(rawUrl)
+		if err != nil {
+			t.Fatalf("unexpected error: want nil, got %v", err)
+		}
+		return u.Host, u.Path
+	}
+
+	t.Run("存储桶域名", func(t *testing.T) {
+		for _, bucket := range []string{"examplebucket", "examplebucket-1250000000"} {
+			if v := cos.BucketHost(bucket, "1250000000", "ap-guangzhou"); v != bucketHost {
+				t.Errorf("unexpected host: want %v, got %v", bucketHost, v)
+			}
+		}
+		client := cos.NewBucketClient("examplebucket", "1250000000", "ap-guangzhou", appKey, appSecret,
+			cos.WithHttpClient(MockHttpClient(checkHost(t, bucketHost, "/ping"))))
+		if err := client.Ping(context.Background()); err != nil {
+			t.Errorf("unexpected error: want nil, got %v", err)
+		}
+	})
+
+	t.Run("全球加速", func(t *testing.T) {
+		const accelerateHost = "examplebucket-1250000000.cos.accelerate.myqcloud.com"
+		client := cos.NewBucketClient("examplebucket", "1250000000", "ap-guangzhou", appKey, appSecret,
+			cos.WithAccelerate(), cos.WithHttpClient(MockHttpClient(checkHost(t, accelerateHost, "/ping"))))
+		if err := client.Ping(context.Background()); err != nil {
+			t.Errorf("unexpected error: want nil, got %v", err)
+		}
+		if h, _ := urlHost(client.GetDownloadUrl("a.txt", cos.AuthExpirationTime)); h != accelerateHost {
+			t.Errorf("unexpected host: want %v, got %v", accelerateHost, h)
+		}
+
+		// 单次调用使用全球加速。
+		client = cos.NewClient(bucketHost, appKey, appSecret)
+		err := client.Upload(context.Background(), "a.txt", []byte("a"), cos.WithAccelerate(), cos.WithNonCheckCrc64(),
+			cos.WithHttpClient(MockHttpClient(checkHost(t, accelerateHost, "/a.txt"))))
+		if err != nil {
+			t.Errorf("unexpected error: want nil, got %v", err)
+		}
+	})
+
+	t.Run("自定义域名", func(t *testing.T) {
+		client := cos.NewBucketClient("examplebucket", "1250000000", "ap-guangzhou", appKey, appSecret,
+			cos.WithAccelerate(), cos.WithCustomDomain("cos.example.com"), cos.WithDownloadDomain("cdn.example.com"),
+			cos.WithHttps(), cos.WithHttpClient(MockHttpClient(checkHost(t, "cos.example.com", "/ping"))))
+		if err := client.Ping(context.Background()); err != nil {
+			t.Errorf("unexpected error: want nil, got %v", err)
+		}
+		if h, _ := urlHost(client.GetDownloadUrl("a.txt", cos.AuthExpirationTime)); h != "cdn.example.com" {
+			t.Errorf("unexpected host: want cdn.example.com, got %v", h)
+		}
+		if h, _ := urlHost(client.PresignUrl(http.MethodPut, "a.txt", nil, nil, cos.AuthExpirationTime)); h !=
+			"cos.example.com" {
+			t.Errorf("unexpected host: want cos.example.com, got %v", h)
+		}
+		if h, _ := urlHost(client.GetPostForm(nil).Url); h != "cos.example.com" {
+			t.Errorf("unexpected host: want cos.example.com, got %v", h)
+		}
+	})
+
+	t.Run("路径前缀", func(t *testing.T) {
+		const privateHost = "cos.private.example.com"
+		client := cos.NewClient(privateHost, appKey, appSecret, cos.WithBasePath("/examplebucket/"),
+			cos.WithHttpClient(MockHttpClient(checkHost(t, privateHost, "/examplebucket/ping"))))
+		if err := client.Ping(context.Background()); err != nil {
+			t.Errorf("unexpected error: want nil, got %v", err)
+		}
+
+		// 签名链接和表单地址带上前缀。
+		rawUrl := client.PresignUrl(http.MethodGet, "dir/a.txt", nil, nil, cos.AuthExpirationTime)
+		if _, p := urlHost(rawUrl); p != "/examplebucket/dir/a.txt" {
+			t.Errorf("unexpected path: want /examplebucket/dir/a.txt, got %v", p)
+		}
+		u, _ := url.Parse(rawUrl)
+		query := u.Query()
+		sign := query.Get("sign")
+		query.Del("sign")
+		if !CheckAuthorization(sign, u.Path, http.MethodGet, nil, query) {
+			t.Errorf("unexpected sign: got %v", sign)
+		}
+		if _, p := urlHost(client.GetPostForm(nil).Url); p != "/examplebucket/" {
+			t.Errorf("unexpected path: want /examplebucket/, got %v", p)
+		}
+
+		// 错误中的对象键不带前缀。
+		fn := func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusNotFound, Body: NewReader([]byte(
+				`<Error><Code>NoSuchKey</Code></Error>`), nil, nil, nil)}, nil
+		}
+		_, _, err := client.Download(context.Background(), "dir/a.txt", cos.WithHttpClient(MockHttpClient(fn)))
+		var cosErr *cos.Error
+		if !errors.As(err, &cosErr) || cosErr.Key != "dir/a.txt" {
+			t.Errorf("unexpected error: got %v", err)
+		}
+
+		// 复制源带上前缀。
+		fn = func(req *http.Request) (*http.Response, error) {
+			if req.Method == http.MethodPut {
+				want := privateHost + "/examplebucket/src.txt"
+				if v := req.Header.Get("x-cos-copy-source"); v != want {
+					t.Errorf("unexpected copy source: want %v, got %v", want, v)
+				}
+				return &http.Response{StatusCode: http.StatusOK, Body: NewReader([]byte(
+					"<CopyObjectResult><CRC64>1</CRC64></CopyObjectResult>"), nil, nil, nil)}, nil
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: NewReader(nil, nil, nil, nil)}, nil
+		}
+		err = client.Copy(context.Background(), "src.txt", "dst.txt", cos.WithHttpClient(MockHttpClient(fn)))
+		if err != nil {
+			t.Errorf("unexpected error: want nil, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,177 @@
+/*
+ * Copyright (c) 2025 ivfzhou
+ * tencent-cos-object-api is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package cos
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotExists 文件不存在。
+	ErrNotExists = errors.New("file not found")
+	// ErrAccessDenied 没有访问权限。
+	ErrAccessDenied = errors.New("access denied")
+	// ErrNoSuchBucket 存储桶不存在。
+	ErrNoSuchBucket = errors.New("no such bucket")
+	// ErrNoSuchUpload 分片上传任务不存在。
+	ErrNoSuchUpload = errors.New("no such upload")
+	// ErrSlowDown 请求频率过高，被服务端限流。
+	ErrSlowDown = errors.New("slow down")
+	// ErrRequestTimeTooSkewed 本地时间与服务端时间相差过大。
+	ErrRequestTimeTooSkewed = errors.New("request time too skewed")
+	// ErrSignatureDoesNotMatch 签名不匹配。
+	ErrSignatureDoesNotMatch = errors.New("signature does not match")
+	// ErrInvalidAccessKeyId 密钥 ID 无效。
+	ErrInvalidAccessKeyId = errors.New("invalid access key id")
+	// ErrInvalidObjectState 文件是归档存储，需要回热后才能读取。
+	ErrInvalidObjectState = errors.New("invalid object state")
+	// ErrRestoreAlreadyInProgress 文件已在回热中。
+	ErrRestoreAlreadyInProgress = errors.New("restore already in progress")
+	// ErrChecksumMismatch 本地计算的 CRC64 与服务端返回的不一致。
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+	// ErrNotModified 文件没有被修改，条件请求的响应码为 304。
+	ErrNotModified = errors.New("not modified")
+	// ErrPreconditionFailed 条件请求的条件不满足，响应码为 412。
+	ErrPreconditionFailed = errors.New("precondition failed")
+)
+
+// 错误码与哨兵错误的对应关系。
+var codeErrors = map[string]error{
+	"NoSuchKey":                ErrNotExists,
+	"AccessDenied":             ErrAccessDenied,
+	"NoSuchBucket":             ErrNoSuchBucket,
+	"NoSuchUpload":             ErrNoSuchUpload,
+	"SlowDown":                 ErrSlowDown,
+	"RequestTimeTooSkewed":     ErrRequestTimeTooSkewed,
+	"SignatureDoesNotMatch":    ErrSignatureDoesNotMatch,
+	"InvalidAccessKeyId":       ErrInvalidAccessKeyId,
+	"InvalidObjectState":       ErrInvalidObjectState,
+	"RestoreAlreadyInProgress": ErrRestoreAlreadyInProgress,
+	"PreconditionFailed":       ErrPreconditionFailed,
+}
+
+// 没有响应体时，响应码与哨兵错误的对应关系。HEAD 请求的响应没有响应体，只能根据响应码判断。
+var statusErrors = map[int]error{
+	http.StatusNotFound:           ErrNotExists,
+	http.StatusForbidden:          ErrAccessDenied,
+	http.StatusServiceUnavailable: ErrSlowDown,
+	http.StatusNotModified:        ErrNotModified,
+	http.StatusPreconditionFailed: ErrPreconditionFailed,
+}
+
+// Error COS 服务端返回的错误信息。可使用 errors.As 获取，使用 errors.Is 与哨兵错误比较。
+type Error struct {
+	// StatusCode HTTP 响应码。
+	StatusCode int
+	// Code 错误码。
+	Code string
+	// Message 错误描述。
+	Message string
+	// RequestId 请求 ID。
+	RequestId string
+	// TraceId 错误 ID。
+	TraceId string
+	// Method HTTP 请求方法。
+	Method string
+	// Key 请求的对象键。
+	Key string
+
+	body       string
+	retryAfter time.Duration
+	serverTime time.Time
+}
+
+// Error 错误描述。
+func (e *Error) Error() string {
+	if len(e.Code) <= 0 {
+		return fmt.Sprintf("status code is %d, method is %s, key is %s, request id is %s, rspBody is %s",
+			e.StatusCode, e.Method, e.Key, e.RequestId, e.body)
+	}
+	return fmt.Sprintf("status code is %d, method is %s, key is %s, code is %s, message is %s, request id is %s, "+
+		"trace id is %s", e.StatusCode, e.Method, e.Key, e.Code, e.Message, e.RequestId, e.TraceId)
+}
+
+// Is 判断是否是对应错误码的哨兵错误。
+func (e *Error) Is(target error) bool {
+	if err, ok := codeErrors[e.Code]; ok {
+		return err == target
+	}
+	// 错误码没有对应的哨兵错误时，如 NoSuchVersion 或没有响应体的 HEAD 请求和 304 响应，根据响应码判断。
+	return statusErrors[e.StatusCode] == target && target != nil
+}
+
+// ChecksumError 本地计算的 CRC64 与服务端返回的不一致。可使用 errors.Is 与 ErrChecksumMismatch 比较。
+type ChecksumError struct {
+	// FileId 文件 ID。
+	FileId string
+	// Local 本地计算的 CRC64。
+	Local uint64
+	// Remote 服务端返回的 CRC64。
+	Remote uint64
+}
+
+// Error 错误描述。
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("crc64 checksum mismatch, file id is %s, local is %d, remote is %d", e.FileId, e.Local, e.Remote)
+}
+
+// Is 判断是否是 ErrChecksumMismatch。
+func (e *ChecksumError) Is(target error) bool {
+	return target == ErrChecksumMismatch
+}
+
+// 从失败的响应中解析出错误信息。
+func newError(method, path string, rsp *http.Response, body []byte) *Error {
+	e := &Error{
+		StatusCode: rsp.StatusCode,
+		Method:     method,
+		Key:        strings.TrimLeft(path, "/"),
+		RequestId:  rsp.Header.Get("x-cos-request-id"),
+		body:       string(body),
+	}
+	if v := rsp.Header.Get("Retry-After"); len(v) > 0 {
+		if seconds, err := strconv.Atoi(v); err == nil {
+			e.retryAfter = time.Duration(seconds) * time.Second
+		} else if t, err := http.ParseTime(v); err == nil {
+			e.retryAfter = time.Until(t)
+		}
+	}
+	if v := rsp.Header.Get("Date"); len(v) > 0 {
+		e.serverTime, _ = http.ParseTime(v)
+	}
+
+	// 解析响应体。
+	var rspData struct {
+		XMLName   xml.Name `xml:"Error"`
+		Code      string
+		Message   string
+		RequestId string
+		TraceId   string
+	}
+	if len(body) > 0 && xml.Unmarshal(body, &rspData) == nil {
+		e.Code = rspData.Code
+		e.Message = rspData.Message
+		e.TraceId = rspData.TraceId
+		if len(rspData.RequestId) > 0 {
+			e.RequestId = rspData.RequestId
+		}
+	}
+
+	return e
+}
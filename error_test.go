@@ -0,0 +1,184 @@
+/*
+ * Copyright (c) 2025 ivfzhou
+ * tencent-cos-object-api is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package cos_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	cos "gitee.com/ivfzhou/tencent-cos-object-api"
+)
+
+func TestError(t *testing.T) {
+	t.Run("解析错误响应体", func(t *testing.T) {
+		cases := []struct {
+			statusCode int
+			code       string
+			sentinel   error
+		}{
+			{http.StatusNotFound, "NoSuchKey", cos.ErrNotExists},
+			{http.StatusForbidden, "AccessDenied", cos.ErrAccessDenied},
+			{http.StatusNotFound, "NoSuchBucket", cos.ErrNoSuchBucket},
+			{http.StatusNotFound, "NoSuchUpload", cos.ErrNoSuchUpload},
+			{http.StatusServiceUnavailable, "SlowDown", cos.ErrSlowDown},
+			{http.StatusForbidden, "RequestTimeTooSkewed", cos.ErrRequestTimeTooSkewed},
+			{http.StatusForbidden, "SignatureDoesNotMatch", cos.ErrSignatureDoesNotMatch},
+			{http.StatusForbidden, "InvalidAccessKeyId", cos.ErrInvalidAccessKeyId},
+		}
+		for _, c := range cases {
+			atomic.StoreInt32(&CloseCount, 0)
+			fileId := "ivfzhou_test_file"
+			fn := func(req *http.Request) (*http.Response, error) {
+				body := "<Error><Code>" + c.code + "</Code><Message>expected message</Message>" +
+					"<RequestId>expected request id</RequestId><TraceId>expected trace id</TraceId></Error>"
+				return &http.Response{
+					StatusCode: c.statusCode,
+					Body:       NewReader([]byte(body), nil, nil, nil),
+				}, nil
+			}
+			client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)))
+			err := client.Delete(context.Background(), fileId)
+			var cosErr *cos.Error
+			if !errors.As(err, &cosErr) {
+				t.Fatalf("unexpected error: want *cos.Error, got %v", err)
+			}
+			if cosErr.StatusCode != c.statusCode {
+				t.Errorf("unexpected status code: want %v, got %v", c.statusCode, cosErr.StatusCode)
+			}
+			if cosErr.Code != c.code {
+				t.Errorf("unexpected code: want %v, got %v", c.code, cosErr.Code)
+			}
+			if cosErr.Message != "expected message" {
+				t.Errorf("unexpected message: want expected message, got %v", cosErr.Message)
+			}
+			if cosErr.RequestId != "expected request id" {
+				t.Errorf("unexpected request id: want expected request id, got %v", cosErr.RequestId)
+			}
+			if cosErr.TraceId != "expected trace id" {
+				t.Errorf("unexpected trace id: want expected trace id, got %v", cosErr.TraceId)
+			}
+			if cosErr.Method != http.MethodDelete {
+				t.Errorf("unexpected method: want %v, got %v", http.MethodDelete, cosErr.Method)
+			}
+			if cosErr.Key != fileId {
+				t.Errorf("unexpected key: want %v, got %v", fileId, cosErr.Key)
+			}
+			if !errors.Is(err, c.sentinel) {
+				t.Errorf("unexpected error: want %v, got %v", c.sentinel, err)
+			}
+			if c.sentinel != cos.ErrNotExists && errors.Is(err, cos.ErrNotExists) {
+				t.Errorf("unexpected error: want not %v, got %v", cos.ErrNotExists, err)
+			}
+			if closeCount := atomic.LoadInt32(&CloseCount); closeCount != 0 {
+				t.Errorf("unexpected close count: want 0, got %v", closeCount)
+			}
+		}
+	})
+
+	t.Run("没有响应体", func(t *testing.T) {
+		atomic.StoreInt32(&CloseCount, 0)
+		fn := func(req *http.Request) (*http.Response, error) {
+			header := http.Header{}
+			header.Set("x-cos-request-id", "expected request id")
+			return &http.Response{
+				StatusCode: http.StatusNotFound,
+				Header:     header,
+				Body:       NewReader(nil, nil, nil, nil),
+			}, nil
+		}
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)))
+		_, err := client.Info(context.Background(), "ivfzhou_test_file")
+		if !errors.Is(err, cos.ErrNotExists) {
+			t.Errorf("unexpected error: want %v, got %v", cos.ErrNotExists, err)
+		}
+		var cosErr *cos.Error
+		if !errors.As(err, &cosErr) {
+			t.Fatalf("unexpected error: want *cos.Error, got %v", err)
+		}
+		if cosErr.RequestId != "expected request id" {
+			t.Errorf("unexpected request id: want expected request id, got %v", cosErr.RequestId)
+		}
+		if closeCount := atomic.LoadInt32(&CloseCount); closeCount != 0 {
+			t.Errorf("unexpected close count: want 0, got %v", closeCount)
+		}
+	})
+
+	t.Run("未知错误码", func(t *testing.T) {
+		fn := func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusNotFound,
+				Body:       NewReader([]byte("<Error><Code>NoSuchVersion</Code></Error>"), nil, nil, nil),
+			}, nil
+		}
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)))
+		_, _, err := client.Download(context.Background(), "ivfzhou_test_file", cos.WithVersionId("v1"))
+		if !errors.Is(err, cos.ErrNotExists) {
+			t.Errorf("unexpected error: want %v, got %v", cos.ErrNotExists, err)
+		}
+		if errors.Is(err, cos.ErrNoSuchUpload) {
+			t.Errorf("unexpected error: want not %v, got %v", cos.ErrNoSuchUpload, err)
+		}
+		exist, err := client.Exist(context.Background(), "ivfzhou_test_file")
+		if err != nil || exist {
+			t.Errorf("unexpected exist: want false, got %v, %v", exist, err)
+		}
+	})
+
+	t.Run("HEAD 无权限", func(t *testing.T) {
+		fn := func(req *http.Request) (*http.Response, error) {
+			if req.Method != http.MethodHead {
+				t.Errorf("unexpected method: want HEAD, got %v", req.Method)
+			}
+			return &http.Response{StatusCode: http.StatusForbidden, Body: NewReader(nil, nil, nil, nil)}, nil
+		}
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)))
+		_, err := client.Info(context.Background(), "ivfzhou_test_file")
+		if !errors.Is(err, cos.ErrAccessDenied) || errors.Is(err, cos.ErrNotExists) {
+			t.Errorf("unexpected error: want %v, got %v", cos.ErrAccessDenied, err)
+		}
+		exist, err := client.Exist(context.Background(), "ivfzhou_test_file")
+		if !errors.Is(err, cos.ErrAccessDenied) || exist {
+			t.Errorf("unexpected exist: want false, %v, got %v, %v", cos.ErrAccessDenied, exist, err)
+		}
+		_, _, err = client.Download(context.Background(), "ivfzhou_test_file")
+		if !errors.Is(err, cos.ErrAccessDenied) {
+			t.Errorf("unexpected error: want %v, got %v", cos.ErrAccessDenied, err)
+		}
+		err = client.Copy(context.Background(), "ivfzhou_test_file", "ivfzhou_test_file2")
+		if !errors.Is(err, cos.ErrAccessDenied) {
+			t.Errorf("unexpected error: want %v, got %v", cos.ErrAccessDenied, err)
+		}
+	})
+
+	t.Run("非 XML 响应体", func(t *testing.T) {
+		expectedErr := "expected error"
+		fn := func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusInternalServerError,
+				Body:       NewReader([]byte(expectedErr), nil, nil, nil),
+			}, nil
+		}
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)))
+		err := client.Delete(context.Background(), "ivfzhou_test_file")
+		if err == nil || !strings.Contains(err.Error(), expectedErr) {
+			t.Errorf("unexpected error: want %v, got %v", expectedErr, err)
+		}
+		if errors.Is(err, cos.ErrNotExists) {
+			t.Errorf("unexpected error: want not %v, got %v", cos.ErrNotExists, err)
+		}
+	})
+}
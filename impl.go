@@ -18,4 +18,8 @@ type impl struct {
 	Downloader
 	Deleter
 	Querier
+	Restorer
+	Copier
+	Mover
+	FormUploader
 }
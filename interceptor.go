@@ -0,0 +1,86 @@
+/*
+ * Copyright (c) 2025 ivfzhou
+ * tencent-cos-object-api is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package cos
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// 请求的操作名称，与 COS API 的名称对应。
+const (
+	OperationHeadObject              = "HeadObject"
+	OperationGetObject               = "GetObject"
+	OperationPutObject               = "PutObject"
+	OperationPutObjectCopy           = "PutObjectCopy"
+	OperationDeleteObject            = "DeleteObject"
+	OperationDeleteMultipleObjects   = "DeleteMultipleObjects"
+	OperationPostObjectRestore       = "PostObjectRestore"
+	OperationGetBucket               = "GetBucket"
+	OperationListObjectVersions      = "ListObjectVersions"
+	OperationInitiateMultipartUpload = "InitiateMultipartUpload"
+	OperationUploadPart              = "UploadPart"
+	OperationUploadPartCopy          = "UploadPartCopy"
+	OperationListParts               = "ListParts"
+	OperationCompleteMultipartUpload = "CompleteMultipartUpload"
+	OperationAbortMultipartUpload    = "AbortMultipartUpload"
+)
+
+// Operation 一次发往 COS 的 HTTP 请求。
+type Operation struct {
+	// Name 操作名称，如 OperationPutObject。
+	Name string
+	// Key 对象键，列举文件、批量删除等存储桶级别的操作为空。
+	Key string
+	// Request 已签名的 HTTP 请求的副本，请求结束后仍可保留。拦截器可以修改请求头，新增的请求头不在签名范围内。
+	// 请求体与原请求共用，只能由 next 读取。
+	Request *http.Request
+}
+
+// Handler 发送 HTTP 请求。响应码不是 2xx 时返回 *Error。
+type Handler func(ctx context.Context, op *Operation) (*http.Response, error)
+
+// Interceptor 拦截每一次 HTTP 请求，包括分片、列举、删除和每一次重试。调用 next 继续发送请求，不调用则直接返回结果。
+type Interceptor func(ctx context.Context, op *Operation, next Handler) (*http.Response, error)
+
+// RequestId 获取 COS 的请求 ID。请求失败时从 *Error 中获取。
+func RequestId(rsp *http.Response, err error) string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.RequestId
+	}
+	if rsp != nil {
+		return rsp.Header.Get("x-cos-request-id")
+	}
+	return ""
+}
+
+// 经过拦截器发送一次 HTTP 请求。
+func (c *baseImpl) invoke(ctx context.Context, op *Operation) (*http.Response, error) {
+	handler := func(ctx context.Context, op *Operation) (*http.Response, error) {
+		return c.doHttp(ctx, op.Request)
+	}
+	for i := len(c.interceptors) - 1; i >= 0; i-- {
+		interceptor, next := c.interceptors[i], handler
+		handler = func(ctx context.Context, op *Operation) (*http.Response, error) {
+			return interceptor(ctx, op, next)
+		}
+	}
+
+	rsp, err := handler(ctx, op)
+	if err == nil && rsp == nil {
+		return nil, errors.New("http response object is nil")
+	}
+	return rsp, err
+}
@@ -0,0 +1,184 @@
+/*
+ * Copyright (c) 2025 ivfzhou
+ * tencent-cos-object-api is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package cos_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	cos "gitee.com/ivfzhou/tencent-cos-object-api"
+)
+
+func TestInterceptor(t *testing.T) {
+	t.Run("操作名称", func(t *testing.T) {
+		fn := func(req *http.Request) (*http.Response, error) {
+			_, _ = io.ReadAll(req.Body)
+			if v := req.Header.Get("X-Trace-Id"); v != "trace" {
+				t.Errorf("unexpected trace id: want trace, got %v", v)
+			}
+			header := http.Header{}
+			header.Set("x-cos-request-id", "request id")
+			header.Set("ETag", "etag")
+			body := ""
+			query := req.URL.Query()
+			switch {
+			case query.Has("uploads"):
+				body = "<InitiateMultipartUploadResult><UploadId>upload id</UploadId></InitiateMultipartUploadResult>"
+			case req.Method == http.MethodPost && query.Has("uploadId"):
+				body = "<CompleteMultipartUploadResult><ETag>etag</ETag></CompleteMultipartUploadResult>"
+			case query.Has("delete"):
+				body = "<DeleteResult></DeleteResult>"
+			case req.Method == http.MethodGet:
+				body = "<ListBucketResult></ListBucketResult>"
+			}
+			return &http.Response{StatusCode: http.StatusOK, Header: header,
+				Body: NewReader([]byte(body), nil, nil, nil)}, nil
+		}
+
+		lock := sync.Mutex{}
+		var calls []string
+		var order []int
+		record := func(n int) cos.Interceptor {
+			return func(ctx context.Context, op *cos.Operation, next cos.Handler) (*http.Response, error) {
+				if n == 1 {
+					op.Request.Header.Set("X-Trace-Id", "trace")
+				}
+				rsp, err := next(ctx, op)
+				lock.Lock()
+				defer lock.Unlock()
+				order = append(order, n)
+				if n == 1 {
+					calls = append(calls, op.Name+" "+op.Key+" "+cos.RequestId(rsp, err))
+				}
+				return rsp, err
+			}
+		}
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)),
+			cos.WithPartSize(1024*1024), cos.WithMultiThreshold(1), cos.WithNonCheckCrc64(),
+			cos.WithInterceptors(record(1)), cos.WithInterceptors(record(2)))
+		ctx := context.Background()
+		if err := client.Upload(ctx, "a.bin", make([]byte, 2*1024*1024)); err != nil {
+			t.Fatalf("unexpected error: want nil, got %v", err)
+		}
+		if _, err := client.List(ctx, "dir/", "/", "", 10); err != nil {
+			t.Fatalf("unexpected error: want nil, got %v", err)
+		}
+		if undeleted := client.Deletes(ctx, "a.bin", "b.bin"); len(undeleted) > 0 {
+			t.Fatalf("unexpected undeleted: got %v", undeleted)
+		}
+
+		// 单次调用的拦截器在客户端的拦截器之后执行。
+		if err := client.Delete(ctx, "a.bin", cos.WithInterceptors(record(3))); err != nil {
+			t.Fatalf("unexpected error: want nil, got %v", err)
+		}
+
+		slices.Sort(calls[1:3])
+		want := []string{
+			"InitiateMultipartUpload a.bin request id",
+			"UploadPart a.bin request id",
+			"UploadPart a.bin request id",
+			"ListParts a.bin request id",
+			"CompleteMultipartUpload a.bin request id",
+			"GetBucket  request id",
+			"DeleteMultipleObjects  request id",
+			"DeleteObject a.bin request id",
+		}
+		if !slices.Equal(calls, want) {
+			t.Errorf("unexpected calls: want %v, got %v", want, calls)
+		}
+		if !slices.Equal(order[len(order)-3:], []int{3, 2, 1}) {
+			t.Errorf("unexpected order: want [3 2 1], got %v", order[len(order)-3:])
+		}
+	})
+
+	t.Run("故障注入", func(t *testing.T) {
+		count := 0
+		fn := func(req *http.Request) (*http.Response, error) {
+			count++
+			return &http.Response{StatusCode: http.StatusOK, Body: NewReader(nil, nil, nil, nil)}, nil
+		}
+		injected := 0
+		inject := func(ctx context.Context, op *cos.Operation, next cos.Handler) (*http.Response, error) {
+			if op.Name == cos.OperationPutObject && injected < 2 {
+				injected++
+				return nil, &cos.Error{StatusCode: http.StatusServiceUnavailable, Code: "SlowDown", RequestId: "fake"}
+			}
+			return next(ctx, op)
+		}
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)),
+			cos.WithInterceptors(inject), cos.WithNonCheckCrc64())
+
+		// 不重试时返回注入的错误。
+		err := client.Upload(context.Background(), "a.txt", []byte("a"))
+		if !errors.Is(err, cos.ErrSlowDown) || cos.RequestId(nil, err) != "fake" || count != 0 {
+			t.Errorf("unexpected error: want %v, got %v", cos.ErrSlowDown, err)
+		}
+
+		// 重试时每一次请求都经过拦截器。
+		err = client.Upload(context.Background(), "a.txt", []byte("a"),
+			cos.WithRetryPolicy(&cos.RetryPolicy{MaxAttempts: 2}))
+		if err != nil || injected != 2 || count != 1 {
+			t.Errorf("unexpected result: got %v, %v, %v", err, injected, count)
+		}
+	})
+
+	t.Run("自定义签名", func(t *testing.T) {
+		fn := func(req *http.Request) (*http.Response, error) {
+			if v := req.Header.Get("Authorization"); !strings.HasPrefix(v, "custom ") {
+				t.Errorf("unexpected auth: got %v", v)
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: NewReader(nil, nil, nil, nil)}, nil
+		}
+		auth := func(ctx context.Context, op *cos.Operation, next cos.Handler) (*http.Response, error) {
+			op.Request.Header.Set("Authorization", "custom "+op.Request.Method)
+			return next(ctx, op)
+		}
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)),
+			cos.WithInterceptors(auth))
+		if err := client.Ping(context.Background()); err != nil {
+			t.Errorf("unexpected error: want nil, got %v", err)
+		}
+	})
+
+	t.Run("保留请求", func(t *testing.T) {
+		fn := func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Body: NewReader(nil, nil, nil, nil)}, nil
+		}
+		var kept []*http.Request
+		keep := func(ctx context.Context, op *cos.Operation, next cos.Handler) (*http.Response, error) {
+			kept = append(kept, op.Request)
+			return next(ctx, op)
+		}
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)),
+			cos.WithInterceptors(keep), cos.WithNonCheckCrc64())
+		for _, fileId := range []string{"a.txt", "b.txt"} {
+			if err := client.Upload(context.Background(), fileId, []byte("a")); err != nil {
+				t.Fatalf("unexpected error: want nil, got %v", err)
+			}
+		}
+		if len(kept) != 2 || kept[0] == kept[1] {
+			t.Fatalf("unexpected requests: want 2 distinct, got %v", kept)
+		}
+		for i, fileId := range []string{"/a.txt", "/b.txt"} {
+			if kept[i].Method != http.MethodPut || kept[i].URL == nil || kept[i].URL.Path != fileId {
+				t.Errorf("unexpected request: want PUT %v, got %v %v", fileId, kept[i].Method, kept[i].URL)
+			}
+		}
+	})
+}
@@ -0,0 +1,148 @@
+/*
+ * Copyright (c) 2025 ivfzhou
+ * tencent-cos-object-api is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package cos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// 日志中需要隐藏值的 HTTP 头。
+var redactedHeaders = map[string]bool{
+	"Authorization":                                         true,
+	"X-Cos-Security-Token":                                  true,
+	"X-Cos-Server-Side-Encryption-Customer-Key":             true,
+	"X-Cos-Copy-Source-Server-Side-Encryption-Customer-Key": true,
+}
+
+// Logger 日志接口，*slog.Logger 实现了该接口。
+type Logger interface {
+	// Enabled 是否记录该级别的日志。不记录调试日志时，不再生成每一次 HTTP 请求的日志参数。
+	Enabled(ctx context.Context, level slog.Level) bool
+	// DebugContext 记录调试日志，如每一次 HTTP 请求。
+	DebugContext(ctx context.Context, msg string, args ...any)
+	// ErrorContext 记录不会返回给调用方的错误，如后台取消分片上传失败。
+	ErrorContext(ctx context.Context, msg string, args ...any)
+}
+
+// 隐藏敏感值的 HTTP 头，记录日志时才生成。
+type loggedHeader http.Header
+
+// LogValue 实现 slog.LogValuer。
+func (h loggedHeader) LogValue() slog.Value {
+	attrs := make([]slog.Attr, 0, len(h))
+	for k, v := range h.redacted() {
+		attrs = append(attrs, slog.String(k, v))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// String 实现 fmt.Stringer，用于非 slog 的日志实现。
+func (h loggedHeader) String() string {
+	return fmt.Sprint(h.redacted())
+}
+
+// 合并同名 HTTP 头的值，并隐藏敏感值。
+func (h loggedHeader) redacted() map[string]string {
+	m := make(map[string]string, len(h))
+	for k, v := range h {
+		if redactedHeaders[http.CanonicalHeaderKey(k)] {
+			m[k] = "REDACTED"
+		} else {
+			m[k] = strings.Join(v, ",")
+		}
+	}
+	return m
+}
+
+// 获取日志，未设置时使用 slog.Default()。
+func (c *baseImpl) log() Logger {
+	if c.logger == nil {
+		return slog.Default()
+	}
+	return c.logger
+}
+
+// 记录不会返回给调用方的错误。err 为 nil 时不记录。
+func (c *baseImpl) logError(ctx context.Context, msg string, err error, args ...any) {
+	if err != nil {
+		c.log().ErrorContext(ctx, msg, append(args, "error", err)...)
+	}
+}
+
+// 记录一次 HTTP 请求。retries 是此前已重试的次数。
+func (c *baseImpl) logRequest(ctx context.Context, op *Operation, rsp *http.Response, err error,
+	start time.Time, retries int) {
+
+	logger := c.log()
+	if !logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	req := op.Request
+	args := []any{
+		"operation", op.Name,
+		"method", req.Method,
+		"key", op.Key,
+		"host", req.Host,
+		"duration", time.Since(start),
+		"retries", retries,
+		"header", loggedHeader(req.Header),
+	}
+	if rsp != nil {
+		args = append(args, "status", rsp.StatusCode, "requestId", rsp.Header.Get("x-cos-request-id"))
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		args = append(args, "status", e.StatusCode, "requestId", e.RequestId, "code", e.Code)
+	}
+	if err != nil {
+		args = append(args, "error", err)
+	}
+	logger.DebugContext(ctx, "cos request", args...)
+}
+
+// 读取响应体并关闭。
+func (c *baseImpl) readAndClose(rsp *http.Response) []byte {
+	if rsp != nil && rsp.Body != nil {
+		bs, err := io.ReadAll(rsp.Body)
+		c.logError(context.Background(), "read response body failed", err)
+		c.closeRsp(rsp)
+		return bs
+	}
+	return nil
+}
+
+// 关闭流。
+func (c *baseImpl) closeIO(closer io.Closer) {
+	if closer != nil {
+		c.logError(context.Background(), "close failed", closer.Close())
+	}
+}
+
+// 关闭 HTTP 响应对象的响应体。
+func (c *baseImpl) closeRsp(r *http.Response) {
+	if r != nil && r.Body != nil {
+		c.logError(context.Background(), "close response body failed", r.Body.Close())
+	}
+}
+
+// 删除检查点文件。
+func (c *baseImpl) removeCheckpoint(path string) {
+	c.logError(context.Background(), "remove checkpoint failed", removeIfExists(path), "path", path)
+}
@@ -0,0 +1,184 @@
+/*
+ * Copyright (c) 2025 ivfzhou
+ * tencent-cos-object-api is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package cos_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	cos "gitee.com/ivfzhou/tencent-cos-object-api"
+)
+
+type recordLogger struct {
+	lock    sync.Mutex
+	records []string
+	noDebug bool
+}
+
+func (l *recordLogger) Enabled(_ context.Context, level slog.Level) bool {
+	return !l.noDebug || level > slog.LevelDebug
+}
+
+func (l *recordLogger) DebugContext(_ context.Context, msg string, args ...any) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.records = append(l.records, fmt.Sprintln(append([]any{"DEBUG", msg}, args...)...))
+}
+
+func (l *recordLogger) ErrorContext(_ context.Context, msg string, args ...any) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.records = append(l.records, fmt.Sprintln(append([]any{"ERROR", msg}, args...)...))
+}
+
+func (l *recordLogger) find(prefix string) string {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	for _, v := range l.records {
+		if strings.HasPrefix(v, prefix) {
+			return v
+		}
+	}
+	return ""
+}
+
+func TestLogger(t *testing.T) {
+	t.Run("请求日志", func(t *testing.T) {
+		count := 0
+		fn := func(req *http.Request) (*http.Response, error) {
+			count++
+			header := http.Header{}
+			header.Set("x-cos-request-id", fmt.Sprintf("request-%d", count))
+			if count == 1 {
+				return &http.Response{StatusCode: http.StatusServiceUnavailable, Header: header,
+					Body: NewReader([]byte("<Error><Code>ServiceUnavailable</Code></Error>"), nil, nil, nil)}, nil
+			}
+			return &http.Response{StatusCode: http.StatusOK, Header: header, Body: NewReader(nil, nil, nil, nil)}, nil
+		}
+		buf := &bytes.Buffer{}
+		logger := slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)),
+			cos.WithLogger(logger), cos.WithRetryPolicy(&cos.RetryPolicy{MaxAttempts: 2}),
+			cos.WithCredentialProvider(cos.NewStaticCredentialProvider(appKey, appSecret, "secret-token")))
+		if err := client.Ping(context.Background()); err != nil {
+			t.Fatalf("unexpected error: want nil, got %v", err)
+		}
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("unexpected log lines: want 2, got %v", buf.String())
+		}
+		for i, line := range lines {
+			var record struct {
+				Level     string
+				Msg       string
+				Method    string
+				Key       string
+				Status    int
+				RequestId string
+				Retries   int
+				Duration  time.Duration
+				Error     string
+				Header    map[string]string
+			}
+			if err := json.Unmarshal([]byte(line), &record); err != nil {
+				t.Fatalf("unexpected error: want nil, got %v", err)
+			}
+			wantStatus := []int{http.StatusServiceUnavailable, http.StatusOK}[i]
+			if record.Level != "DEBUG" || record.Method != http.MethodHead || record.Key != "ping" ||
+				record.Status != wantStatus || record.RequestId != fmt.Sprintf("request-%d", i+1) || record.Retries != i {
+				t.Errorf("unexpected record: got %v", line)
+			}
+			if (i == 0) != (len(record.Error) > 0) {
+				t.Errorf("unexpected error: got %v", record.Error)
+			}
+			if record.Header["Authorization"] != "REDACTED" || record.Header["X-Cos-Security-Token"] != "REDACTED" ||
+				record.Header["Host"] != host {
+				t.Errorf("unexpected header: got %v", record.Header)
+			}
+		}
+		if strings.Contains(buf.String(), "q-signature") || strings.Contains(buf.String(), "secret-token") {
+			t.Errorf("unexpected log: got %v", buf.String())
+		}
+	})
+
+	t.Run("后台错误", func(t *testing.T) {
+		fn := func(req *http.Request) (*http.Response, error) {
+			_, _ = io.ReadAll(req.Body)
+			switch {
+			case req.Method == http.MethodPost && req.URL.Query().Has("uploads"):
+				return &http.Response{StatusCode: http.StatusOK, Body: NewReader([]byte(
+					"<InitiateMultipartUploadResult><UploadId>upload id</UploadId></InitiateMultipartUploadResult>"),
+					nil, nil, nil)}, nil
+			case req.Method == http.MethodPut && req.URL.Query().Get("partNumber") != "3":
+				header := http.Header{}
+				header.Set("ETag", "etag")
+				return &http.Response{StatusCode: http.StatusOK, Header: header, Body: NewReader(nil, nil, nil, nil)}, nil
+			case req.Method == http.MethodDelete:
+				return &http.Response{StatusCode: http.StatusInternalServerError,
+					Body: NewReader([]byte("<Error><Code>InternalError</Code></Error>"), nil, nil, nil)}, nil
+			}
+			// 最后一个分片失败。
+			return &http.Response{StatusCode: http.StatusForbidden,
+				Body: NewReader([]byte("<Error><Code>AccessDenied</Code></Error>"), nil, nil, nil)}, nil
+		}
+		logger := &recordLogger{}
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)),
+			cos.WithLogger(logger), cos.WithPartSize(1024*1024), cos.WithMultiThreshold(1))
+		err := client.Upload(context.Background(), "a.bin", make([]byte, 3*1024*1024))
+		if err == nil {
+			t.Fatalf("unexpected error: want non-nil, got nil")
+		}
+
+		// 等待后台取消分片上传。
+		record := ""
+		for range 100 {
+			if record = logger.find("ERROR abort multipart upload failed"); len(record) > 0 {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		if !strings.Contains(record, "a.bin") || !strings.Contains(record, "upload id") ||
+			!strings.Contains(record, "InternalError") {
+			t.Errorf("unexpected record: got %v", record)
+		}
+		record = logger.find("DEBUG cos request")
+		if !strings.Contains(record, "Authorization:REDACTED") || strings.Contains(record, "q-signature") {
+			t.Errorf("unexpected record: got %v", record)
+		}
+	})
+
+	t.Run("不记录调试日志", func(t *testing.T) {
+		fn := func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Body: NewReader(nil, nil, nil, nil)}, nil
+		}
+		logger := &recordLogger{noDebug: true}
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)),
+			cos.WithLogger(logger))
+		if err := client.Ping(context.Background()); err != nil {
+			t.Fatalf("unexpected error: want nil, got %v", err)
+		}
+		if record := logger.find("DEBUG"); len(record) > 0 {
+			t.Errorf("unexpected record: want empty, got %v", record)
+		}
+	})
+}
@@ -0,0 +1,35 @@
+/*
+ * Copyright (c) 2025 ivfzhou
+ * tencent-cos-object-api is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package cos
+
+import "context"
+
+// MoveReport 批量移动文件的结果。
+type MoveReport struct {
+	// Moved 移动成功的文件，键是源文件 ID，值是目标文件 ID。
+	Moved map[string]string
+	// Failed 移动失败的文件，键是源文件 ID。复制成功但删除源文件失败时，源文件和目标文件同时存在。
+	Failed map[string]error
+}
+
+// Mover 移动文件，在服务端复制后删除源文件。opts 与 Copier 相同，可用 WithCopySourceHost 从其它存储桶移动。
+type Mover interface {
+	// Move 移动文件。复制成功后才删除源文件。使用 WithVersionId 时移动源文件的指定版本，该版本会被永久删除。
+	Move(ctx context.Context, srcId, dstId string, opts ...option) error
+
+	// MovePrefix 移动目录 srcPrefix 下所有层级的文件到目录 dstPrefix 下，保持相对路径不变。前缀末尾没有 / 时自动补上。
+	// 不支持 WithVersionId。列举出的文件 ID 原样移动，以 / 结尾的目录占位文件也一并移动。
+	// 逐页列举文件，使用 NumRoutines 个协程并发复制，只删除复制成功的源文件。
+	// 列举文件失败或上下文终止时返回 err，已处理的文件记录在 report 中。
+	MovePrefix(ctx context.Context, srcPrefix, dstPrefix string, opts ...option) (report *MoveReport, err error)
+}
@@ -0,0 +1,129 @@
+/*
+ * Copyright (c) 2025 ivfzhou
+ * tencent-cos-object-api is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package cos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	gu "gitee.com/ivfzhou/goroutine-util"
+)
+
+type moveImpl struct {
+	*copyImpl
+}
+
+// Move 移动文件。
+func (c *moveImpl) Move(ctx context.Context, srcId, dstId string, opts ...option) error {
+	c = c.with(opts)
+	if suitFileId(srcId) == suitFileId(dstId) && c.copySource() == c.baseImpl {
+		return errors.New("srcId and dstId are the same")
+	}
+
+	// 复制成功后删除源文件。
+	if err := c.Copy(ctx, srcId, dstId); err != nil {
+		return err
+	}
+	return (&deleteImpl{c.copySource()}).Delete(ctx, srcId)
+}
+
+// MovePrefix 移动前缀下的所有文件。
+func (c *moveImpl) MovePrefix(ctx context.Context, srcPrefix, dstPrefix string, opts ...option) (
+	*MoveReport, error) {
+
+	srcPrefix = suitDirPrefix(srcPrefix)
+	dstPrefix = suitDirPrefix(dstPrefix)
+	c = c.with(opts)
+	if len(c.versionId) > 0 {
+		return nil, errors.New("versionId is not supported when moving prefix")
+	}
+	src := c.copySource()
+	if src == c.baseImpl && strings.HasPrefix(dstPrefix, srcPrefix) {
+		return nil, errors.New("dstPrefix must not be under srcPrefix")
+	}
+
+	// 文件间已经并发，单个文件的分片串行复制，总并发数不超过 NumRoutines。
+	cc := c.copyImpl.with([]option{WithNumRoutines(1)})
+
+	report := &MoveReport{Moved: make(map[string]string), Failed: make(map[string]error)}
+	for marker := ""; ; {
+		// 列举一页文件。
+		files, nextMarker, err := src.listFiles(ctx, srcPrefix, "", marker, 1000)
+		if err != nil {
+			return report, err
+		}
+
+		// 并发复制，单个文件失败不影响其它文件。
+		copied := make(map[string]string, len(files))
+		lock := sync.Mutex{}
+		run, wait := gu.NewRunner(ctx, c.numRoutines, func(ctx context.Context, f *File) error {
+			dstId := dstPrefix + strings.TrimPrefix(f.ID, srcPrefix)
+			err := cc.copyKey(ctx, f.ID, dstId)
+			lock.Lock()
+			defer lock.Unlock()
+			if err != nil {
+				report.Failed[f.ID] = err
+			} else {
+				copied[f.ID] = dstId
+			}
+			return nil
+		})
+		for _, v := range files {
+			if err = run(v, false); err != nil {
+				break
+			}
+		}
+		if err == nil {
+			err = wait(true)
+		} else {
+			_ = wait(false)
+		}
+
+		// 只删除复制成功的源文件。列举出的文件 ID 原样复制、删除，目录占位文件以 / 结尾。
+		if len(copied) > 0 {
+			ids := make([]FileVersionId, 0, len(copied))
+			for k := range copied {
+				ids = append(ids, FileVersionId{FileId: k})
+			}
+			undeleted := (&deleteImpl{src}).deleteKeys(context.WithoutCancel(ctx), ids)
+			for k, v := range copied {
+				if e, ok := undeleted[FileVersionId{FileId: k}]; ok {
+					report.Failed[k] = fmt.Errorf("copied to %s but failed to delete source: %w", v, e)
+				} else {
+					report.Moved[k] = v
+				}
+			}
+		}
+		if err != nil {
+			return report, err
+		}
+
+		// 没有下一页了。
+		if len(nextMarker) <= 0 {
+			return report, nil
+		}
+		marker = nextMarker
+	}
+}
+
+// 使用调用参数覆盖客户端参数。
+func (c *moveImpl) with(opts []option) *moveImpl {
+	cc := c.copyImpl.with(opts)
+	if cc == c.copyImpl {
+		return c
+	}
+	return &moveImpl{cc}
+}
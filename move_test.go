@@ -0,0 +1,149 @@
+/*
+ * Copyright (c) 2025 ivfzhou
+ * tencent-cos-object-api is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package cos_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	cos "gitee.com/ivfzhou/tencent-cos-object-api"
+)
+
+func TestMove(t *testing.T) {
+	t.Run("正常运行", func(t *testing.T) {
+		bucket := NewMockBucket("a/1.txt", "b.txt")
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(bucket.Handle)))
+		if err := client.Move(context.Background(), "a/1.txt", "c/1.txt"); err != nil {
+			t.Errorf("unexpected error: want nil, got %v", err)
+		}
+		if keys := bucket.Keys(); !slices.Equal(keys, []string{"b.txt", "c/1.txt"}) {
+			t.Errorf("unexpected keys: want [b.txt c/1.txt], got %v", keys)
+		}
+	})
+
+	t.Run("复制失败", func(t *testing.T) {
+		bucket := NewMockBucket("a/1.txt")
+		bucket.failCopy["a/1.txt"] = true
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(bucket.Handle)))
+		err := client.Move(context.Background(), "a/1.txt", "c/1.txt")
+		var cosErr *cos.Error
+		if !errors.As(err, &cosErr) || cosErr.Code != "InternalError" {
+			t.Errorf("unexpected error: want InternalError, got %v", err)
+		}
+		if keys := bucket.Keys(); !slices.Equal(keys, []string{"a/1.txt"}) {
+			t.Errorf("unexpected keys: want [a/1.txt], got %v", keys)
+		}
+	})
+
+	t.Run("相同文件", func(t *testing.T) {
+		err := cos.NewClient(host, appKey, appSecret).Move(context.Background(), "a/1.txt", "/a/1.txt")
+		if err == nil {
+			t.Errorf("unexpected error: want not nil, got %v", err)
+		}
+	})
+}
+
+func TestMovePrefix(t *testing.T) {
+	t.Run("正常运行", func(t *testing.T) {
+		keys := make([]string, 0, 2100)
+		for i := range 2100 {
+			keys = append(keys, "src/"+strconv.Itoa(i/100)+"/"+strconv.Itoa(i))
+		}
+		bucket := NewMockBucket(append(keys, "other/1", "src.txt")...)
+		bucket.failCopy["src/3/300"] = true
+		bucket.failDelete["src/4/400"] = true
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(bucket.Handle)))
+		report, err := client.MovePrefix(context.Background(), "src/", "dst/")
+		if err != nil {
+			t.Fatalf("unexpected error: want nil, got %v", err)
+		}
+		if len(report.Moved) != len(keys)-2 {
+			t.Errorf("unexpected moved: want %v, got %v", len(keys)-2, len(report.Moved))
+		}
+		if report.Moved["src/1/123"] != "dst/1/123" {
+			t.Errorf("unexpected moved: want dst/1/123, got %v", report.Moved["src/1/123"])
+		}
+		if len(report.Failed) != 2 || report.Failed["src/3/300"] == nil || report.Failed["src/4/400"] == nil {
+			t.Errorf("unexpected failed: got %v", report.Failed)
+		}
+		if !errors.Is(report.Failed["src/4/400"], cos.ErrAccessDenied) {
+			t.Errorf("unexpected error: want %v, got %v", cos.ErrAccessDenied, report.Failed["src/4/400"])
+		}
+		left := make([]string, 0)
+		moved := 0
+		for _, v := range bucket.Keys() {
+			if strings.HasPrefix(v, "dst/") {
+				moved++
+			} else {
+				left = append(left, v)
+			}
+		}
+		if want := []string{"other/1", "src.txt", "src/3/300", "src/4/400"}; !slices.Equal(left, want) {
+			t.Errorf("unexpected keys: want %v, got %v", want, left)
+		}
+		if moved != len(keys)-1 {
+			t.Errorf("unexpected moved count: want %v, got %v", len(keys)-1, moved)
+		}
+		if n := atomic.LoadInt32(&bucket.deleteCount); n != 3 {
+			t.Errorf("unexpected delete count: want 3, got %v", n)
+		}
+	})
+
+	t.Run("目标在源前缀下", func(t *testing.T) {
+		client := cos.NewClient(host, appKey, appSecret)
+		for _, v := range [][2]string{{"src/", "src/dst/"}, {"src", "src/dst"}, {"", "dst/"}} {
+			if _, err := client.MovePrefix(context.Background(), v[0], v[1]); err == nil {
+				t.Errorf("unexpected error: want not nil, got %v", err)
+			}
+		}
+		_, err := client.MovePrefix(context.Background(), "src/", "dst/", cos.WithVersionId("v1"))
+		if err == nil {
+			t.Errorf("unexpected error: want not nil, got %v", err)
+		}
+	})
+
+	t.Run("按目录匹配", func(t *testing.T) {
+		bucket := NewMockBucket("a/1", "a/2", "ab/3", "a.txt")
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(bucket.Handle)))
+		report, err := client.MovePrefix(context.Background(), "a", "ab")
+		if err != nil {
+			t.Fatalf("unexpected error: want nil, got %v", err)
+		}
+		if len(report.Moved) != 2 || report.Moved["a/1"] != "ab/1" || len(report.Failed) != 0 {
+			t.Errorf("unexpected report: got %v, %v", report.Moved, report.Failed)
+		}
+		if want := []string{"a.txt", "ab/1", "ab/2", "ab/3"}; !slices.Equal(bucket.Keys(), want) {
+			t.Errorf("unexpected keys: want %v, got %v", want, bucket.Keys())
+		}
+	})
+
+	t.Run("目录占位文件", func(t *testing.T) {
+		bucket := NewMockBucket("src/", "src/sub/", "src/sub/1", "src/sub")
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(bucket.Handle)))
+		report, err := client.MovePrefix(context.Background(), "src", "dst")
+		if err != nil {
+			t.Fatalf("unexpected error: want nil, got %v", err)
+		}
+		if len(report.Moved) != 4 || report.Moved["src/sub/"] != "dst/sub/" || len(report.Failed) != 0 {
+			t.Errorf("unexpected report: got %v, %v", report.Moved, report.Failed)
+		}
+		if want := []string{"dst/", "dst/sub", "dst/sub/", "dst/sub/1"}; !slices.Equal(bucket.Keys(), want) {
+			t.Errorf("unexpected keys: want %v, got %v", want, bucket.Keys())
+		}
+	})
+}
@@ -15,11 +15,12 @@ package cos
 import (
 	"context"
 	"io"
+	"time"
 )
 
 type MultiUploader interface {
-	// InitMultiUpload 初始化分片上传区域。
-	InitMultiUpload(ctx context.Context, fileId string) (uploadId string, err error)
+	// InitMultiUpload 初始化分片上传区域。opts 可设置文件的 HTTP 头和自定义元数据，如 WithContentType、WithMetadata。
+	InitMultiUpload(ctx context.Context, fileId string, opts ...option) (uploadId string, err error)
 
 	// UploadPart 上传分片。
 	UploadPart(ctx context.Context, fileId, uploadId string, partNumber int64, reqBody []byte) error
@@ -31,8 +32,14 @@ type MultiUploader interface {
 	ListFileParts(ctx context.Context, fileId, uploadId string) ([]*FilePartInfo, error)
 
 	// CompleteMultiUpload 结束分片上传。
-	CompleteMultiUpload(ctx context.Context, fileId, uploadId string) error
+	CompleteMultiUpload(ctx context.Context, fileId, uploadId string, opts ...option) error
+
+	// CompleteMultiUploadWithResult 结束分片上传，返回上传结果。
+	CompleteMultiUploadWithResult(ctx context.Context, fileId, uploadId string, opts ...option) (*UploadResult, error)
 
 	// AbortMultiUpload 丢弃上传的分片。
 	AbortMultiUpload(ctx context.Context, fileId, uploadId string) error
+
+	// GetUploadPartUrl 获取分片上传链接，使用 PUT 方法上传。InitMultiUpload 和 CompleteMultiUpload 仍由服务端调用。
+	GetUploadPartUrl(fileId, uploadId string, partNumber int64, expiration time.Duration) string
 }
@@ -21,6 +21,7 @@ import (
 	"net/url"
 	"sort"
 	"strconv"
+	"time"
 )
 
 type multiUploadImpl struct {
@@ -28,28 +29,29 @@ type multiUploadImpl struct {
 }
 
 // InitMultiUpload 初始化分片上传区域。
-func (c *multiUploadImpl) InitMultiUpload(ctx context.Context, fileId string) (string, error) {
+func (c *multiUploadImpl) InitMultiUpload(ctx context.Context, fileId string, opts ...option) (string, error) {
 	fileId = suitFileId(fileId)
 	if len(fileId) <= 0 {
 		return "", errors.New("fileId is invalid")
 	}
+	c = c.with(opts)
 
 	// 生成请求体。
 	query := url.Values{}
 	query.Set("uploads", "")
-	header := http.Header{}
+	header := c.mergeUploadHeader(nil)
 	header.Set("Content-Length", "0")
 	req := c.genReq(http.MethodPost, fileId, query, header, nil)
 
 	// 发送 HTTP 请求。
-	rsp, err := c.sendHttp(ctx, req)
+	rsp, err := c.sendHttp(ctx, OperationInitiateMultipartUpload, req)
 	if err != nil {
 		return "", err
 	}
 
 	// 读取出响应体。
 	rspBody, err := io.ReadAll(rsp.Body)
-	closeRsp(rsp)
+	c.closeRsp(rsp)
 	if err != nil {
 		return "", err
 	}
@@ -79,14 +81,14 @@ func (c *multiUploadImpl) UploadPart(ctx context.Context, fileId, uploadId strin
 	query := url.Values{}
 	query.Set("partNumber", strconv.FormatInt(partNumber, 10))
 	query.Set("uploadId", uploadId)
-	req := c.genReq(http.MethodPut, fileId, query, nil, reqBody)
+	req := c.genReq(http.MethodPut, fileId, query, c.mergeEncryptionHeader(nil), reqBody)
 
 	// 发送 HTTP 请求。
-	rsp, err := c.sendHttp(ctx, req)
+	rsp, err := c.sendHttp(ctx, OperationUploadPart, req)
 	if err != nil {
 		return err
 	}
-	closeRsp(rsp)
+	c.closeRsp(rsp)
 
 	return nil
 }
@@ -100,20 +102,28 @@ func (c *multiUploadImpl) UploadPartByReader(ctx context.Context, fileId, upload
 		return errors.New("fileId is invalid")
 	}
 
+	_, err := c.uploadPart(ctx, fileId, uploadId, partNumber, contentLength, r)
+	return err
+}
+
+// 上传分片，返回分片的 ETag。
+func (c *multiUploadImpl) uploadPart(ctx context.Context, fileId, uploadId string, partNumber,
+	contentLength int64, r io.Reader) (string, error) {
+
 	// 生成请求体。
 	query := url.Values{}
 	query.Set("uploadId", uploadId)
 	query.Set("partNumber", strconv.FormatInt(partNumber, 10))
-	req := c.genReqForReader(http.MethodPut, fileId, query, nil, contentLength, r)
+	req := c.genReqForReader(http.MethodPut, fileId, query, c.mergeEncryptionHeader(nil), contentLength, r)
 
 	// 发送 HTTP 请求。
-	rsp, err := c.sendHttp(ctx, req)
+	rsp, err := c.sendHttp(ctx, OperationUploadPart, req)
 	if err != nil {
-		return err
+		return "", err
 	}
-	closeRsp(rsp)
+	c.closeRsp(rsp)
 
-	return nil
+	return rsp.Header.Get("ETag"), nil
 }
 
 // ListFileParts 获取已上传的分片信息。
@@ -123,7 +133,7 @@ func (c *multiUploadImpl) ListFileParts(ctx context.Context, fileId, uploadId st
 		return nil, errors.New("fileId is invalid")
 	}
 
-	parts := make([]*FilePartInfo, 0, MultiThreshold)
+	parts := make([]*FilePartInfo, 0, c.multiThreshold)
 	next := ""
 	for {
 		// 生成请求体。
@@ -135,14 +145,14 @@ func (c *multiUploadImpl) ListFileParts(ctx context.Context, fileId, uploadId st
 		req := c.genReq(http.MethodGet, fileId, query, nil, nil)
 
 		// 发送请求。
-		rsp, err := c.sendHttp(ctx, req)
+		rsp, err := c.sendHttp(ctx, OperationListParts, req)
 		if err != nil {
 			return nil, err
 		}
 
 		// 读取出响应体。
 		rspBody, err := io.ReadAll(rsp.Body)
-		closeRsp(rsp)
+		c.closeRsp(rsp)
 		if err != nil {
 			return nil, err
 		}
@@ -191,16 +201,32 @@ func (c *multiUploadImpl) ListFileParts(ctx context.Context, fileId, uploadId st
 }
 
 // CompleteMultiUpload 结束分片上传。
-func (c *multiUploadImpl) CompleteMultiUpload(ctx context.Context, fileId, uploadId string) error {
+func (c *multiUploadImpl) CompleteMultiUpload(ctx context.Context, fileId, uploadId string, opts ...option) error {
+	_, err := c.CompleteMultiUploadWithResult(ctx, fileId, uploadId, opts...)
+	return err
+}
+
+// CompleteMultiUploadWithResult 结束分片上传，返回上传结果。
+func (c *multiUploadImpl) CompleteMultiUploadWithResult(ctx context.Context, fileId, uploadId string,
+	opts ...option) (*UploadResult, error) {
+
 	fileId = suitFileId(fileId)
 	if len(fileId) <= 0 {
-		return errors.New("fileId is invalid")
+		return nil, errors.New("fileId is invalid")
 	}
+	c = c.with(opts)
+
+	return c.completeMultiUpload(ctx, fileId, uploadId, c.mergeConditionHeader(nil))
+}
+
+// 合并分片，返回上传结果。header 是合并请求附带的 HTTP 头。
+func (c *multiUploadImpl) completeMultiUpload(ctx context.Context, fileId, uploadId string, header http.Header) (
+	*UploadResult, error) {
 
 	// 获取所有以上传的分片。
 	parts, err := c.ListFileParts(ctx, fileId, uploadId)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// 生成请求体。
@@ -213,25 +239,42 @@ func (c *multiUploadImpl) CompleteMultiUpload(ctx context.Context, fileId, uploa
 	}
 	var req CompleteMultipartUpload
 	req.Parts = make([]*PartInfo, len(parts))
+	size := int64(0)
 	for i, v := range parts {
 		req.Parts[i] = &PartInfo{
 			PartNumber: strconv.Itoa(v.PartNumber),
 			ETag:       v.EntityTag,
 		}
+		size += v.Size
 	}
 	reqBody, _ := xml.Marshal(req)
 
 	// 发送 HTTP 请求。
 	query := url.Values{}
 	query.Set("uploadId", uploadId)
-	header := http.Header{}
-	rsp, err := c.sendHttp(ctx, c.genReq(http.MethodPost, fileId, query, header, reqBody))
+	httpReq := c.genReq(http.MethodPost, fileId, query, header, reqBody)
+	rsp, err := c.sendHttp(ctx, OperationCompleteMultipartUpload, httpReq)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	closeRsp(rsp)
+	rspBody := c.readAndClose(rsp)
 
-	return nil
+	// 解析响应体，合并失败时服务端也可能返回 200 响应码。
+	var rspData struct {
+		XMLName xml.Name
+		ETag    string
+	}
+	result := newUploadResult(rsp.Header, size, len(parts))
+	if xml.Unmarshal(rspBody, &rspData) == nil {
+		if rspData.XMLName.Local == "Error" {
+			return nil, newError(http.MethodPost, fileId, rsp, rspBody)
+		}
+		if len(rspData.ETag) > 0 {
+			result.EntityTag = rspData.ETag
+		}
+	}
+
+	return result, nil
 }
 
 // AbortMultiUpload 丢弃上传的分片。
@@ -246,11 +289,36 @@ func (c *multiUploadImpl) AbortMultiUpload(ctx context.Context, fileId, uploadId
 	query.Set("uploadId", uploadId)
 	req := c.genReq(http.MethodDelete, fileId, query, nil, nil)
 
-	rsp, err := c.sendHttp(ctx, req)
+	rsp, err := c.sendHttp(ctx, OperationAbortMultipartUpload, req)
 	if err != nil {
 		return err
 	}
-	closeRsp(rsp)
+	c.closeRsp(rsp)
 
 	return nil
 }
+
+// 丢弃上传的分片，失败时记录错误。用于上传失败后在后台清理。
+func (c *multiUploadImpl) tryAbortMultiUpload(ctx context.Context, fileId, uploadId string) {
+	err := c.AbortMultiUpload(ctx, fileId, uploadId)
+	c.logError(ctx, "abort multipart upload failed", err, "key", fileId, "uploadId", uploadId)
+}
+
+// GetUploadPartUrl 获取分片上传链接。
+func (c *multiUploadImpl) GetUploadPartUrl(fileId, uploadId string, partNumber int64,
+	expiration time.Duration) string {
+
+	query := url.Values{}
+	query.Set("uploadId", uploadId)
+	query.Set("partNumber", strconv.FormatInt(partNumber, 10))
+	return c.PresignUrl(http.MethodPut, fileId, query, nil, expiration)
+}
+
+// 使用调用参数覆盖客户端参数。
+func (c *multiUploadImpl) with(opts []option) *multiUploadImpl {
+	b := c.baseImpl.with(opts)
+	if b == c.baseImpl {
+		return c
+	}
+	return &multiUploadImpl{b}
+}
@@ -12,14 +12,41 @@
 
 package cos
 
-import "net/http"
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
 
 type options struct {
-	client     *http.Client
-	tls        bool
-	nonUseDisk bool
+	client             *http.Client
+	tls                bool
+	nonUseDisk         bool
+	partSize           int64
+	multiThreshold     int
+	numRoutines        int
+	authExpirationTime time.Duration
+	retryPolicy        *RetryPolicy
+	progress           ProgressFunc
+	uploadHeader       http.Header
+	encryption         *serverSideEncryption
+	nonCheckCrc64      bool
+	copySourceHost     string
+	replaceMetadata    bool
+	dryRun             bool
+	versionId          string
+	conditionHeader    http.Header
+	credentials        *credentialCache
+	accelerate         bool
+	domain             string
+	downloadDomain     string
+	basePath           string
+	logger             Logger
+	interceptors       []Interceptor
 }
 
+// option 客户端参数。可在 NewClient 时设置，也可在上传、下载、查询、复制、删除时单独设置，覆盖客户端的参数。
 type option func(*options)
 
 // WithHttpClient 使用自定义 HTTP 客户端实现。默认使用 http.DefaultClient。
@@ -29,6 +56,15 @@ func WithHttpClient(client *http.Client) option {
 	}
 }
 
+// WithCredentialProvider 从 provider 获取密钥，如 STS 临时密钥，NewClient 的 appKey、secretKey 将被忽略。
+// 密钥会被缓存，在过期前自动重新获取。
+func WithCredentialProvider(provider CredentialProvider) option {
+	credentials := newCredentialCache(provider)
+	return func(o *options) {
+		o.credentials = credentials
+	}
+}
+
 // WithHttps 使用 https 协议。
 func WithHttps() option {
 	return func(o *options) {
@@ -36,9 +72,270 @@ func WithHttps() option {
 	}
 }
 
+// WithAccelerate 使用全球加速域名 <bucket>-<appId>.cos.accelerate.myqcloud.com 访问。
+// 仅对存储桶默认域名生效，存储桶需开启全球加速。
+func WithAccelerate() option {
+	return func(o *options) {
+		o.accelerate = true
+	}
+}
+
+// WithCustomDomain 使用绑定到存储桶的自定义源站域名访问，如内网域名、私有化部署的域名。优先于 WithAccelerate。
+func WithCustomDomain(domain string) option {
+	return func(o *options) {
+		o.domain = domain
+	}
+}
+
+// WithDownloadDomain GetDownloadUrl 生成的下载链接使用的域名，如 CDN 加速域名。默认与请求使用的域名相同。
+func WithDownloadDomain(domain string) option {
+	return func(o *options) {
+		o.downloadDomain = domain
+	}
+}
+
+// WithBasePath 文件路径的前缀，用于路径风格访问的私有化部署，请求路径为 /<basePath>/<fileId>。
+func WithBasePath(basePath string) option {
+	return func(o *options) {
+		o.basePath = strings.Trim(basePath, "/")
+	}
+}
+
+// WithLogger 使用 logger 记录日志，如 *slog.Logger。默认使用 slog.Default()。
+// 后台取消分片上传等不会返回给调用方的错误使用 Error 级别，每一次 HTTP 请求使用 Debug 级别，Authorization 等敏感的 HTTP 头会被隐藏。
+func WithLogger(logger Logger) option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithInterceptors 添加 HTTP 请求的拦截器，按添加顺序由外到内执行。单次调用添加的拦截器在客户端的拦截器之后执行。
+func WithInterceptors(interceptors ...Interceptor) option {
+	return func(o *options) {
+		o.interceptors = append(slices.Clip(o.interceptors), interceptors...)
+	}
+}
+
 // WithNonUseDisk 临时文件数据不放置到外存，而是放置在内存。
 func WithNonUseDisk() option {
 	return func(o *options) {
 		o.nonUseDisk = true
 	}
 }
+
+// WithNonCheckCrc64 上传下载时不校验 CRC64。默认会与服务端返回的 CRC64 比较，不一致时返回 ChecksumError。
+func WithNonCheckCrc64() option {
+	return func(o *options) {
+		o.nonCheckCrc64 = true
+	}
+}
+
+// WithPartSize 分片上传下载时，每个分片的大小。默认使用 PartSize。
+func WithPartSize(partSize int64) option {
+	return func(o *options) {
+		o.partSize = partSize
+	}
+}
+
+// WithMultiThreshold 文件大小超过多少个分片大小后，启用分片模式传输。默认使用 MultiThreshold。
+func WithMultiThreshold(threshold int) option {
+	return func(o *options) {
+		o.multiThreshold = threshold
+	}
+}
+
+// WithNumRoutines 分片上传下载时，并发运行协程的数量。默认使用 NumRoutines。
+func WithNumRoutines(n int) option {
+	return func(o *options) {
+		o.numRoutines = n
+	}
+}
+
+// WithAuthExpirationTime 每一个 HTTP 请求的凭证时效。默认使用 AuthExpirationTime。
+func WithAuthExpirationTime(expiration time.Duration) option {
+	return func(o *options) {
+		o.authExpirationTime = expiration
+	}
+}
+
+// WithRetryPolicy 请求失败后按策略重试，包括分片上传下载中的每一个分片。默认不重试。
+func WithRetryPolicy(policy *RetryPolicy) option {
+	return func(o *options) {
+		o.retryPolicy = policy
+	}
+}
+
+// WithProgress 上传下载时回调传输进度。
+func WithProgress(fn ProgressFunc) option {
+	return func(o *options) {
+		o.progress = fn
+	}
+}
+
+// WithContentType 上传时设置文件的 Content-Type。
+func WithContentType(contentType string) option {
+	return func(o *options) {
+		o.setUploadHeader("Content-Type", contentType)
+	}
+}
+
+// WithContentDisposition 上传时设置文件的 Content-Disposition。
+func WithContentDisposition(disposition string) option {
+	return func(o *options) {
+		o.setUploadHeader("Content-Disposition", disposition)
+	}
+}
+
+// WithCacheControl 上传时设置文件的 Cache-Control。
+func WithCacheControl(cacheControl string) option {
+	return func(o *options) {
+		o.setUploadHeader("Cache-Control", cacheControl)
+	}
+}
+
+// WithContentEncoding 上传时设置文件的 Content-Encoding。
+func WithContentEncoding(encoding string) option {
+	return func(o *options) {
+		o.setUploadHeader("Content-Encoding", encoding)
+	}
+}
+
+// WithExpires 上传时设置文件的 Expires。
+func WithExpires(expires time.Time) option {
+	return func(o *options) {
+		o.setUploadHeader("Expires", expires.UTC().Format(http.TimeFormat))
+	}
+}
+
+// WithStorageClass 上传时设置文件的存储类型。默认使用存储桶的存储类型。
+func WithStorageClass(class StorageClass) option {
+	return func(o *options) {
+		o.setUploadHeader("x-cos-storage-class", string(class))
+	}
+}
+
+// WithSSECOS 使用 COS 托管密钥对上传的文件加密（SSE-COS）。
+func WithSSECOS() option {
+	return func(o *options) {
+		o.encryption = &serverSideEncryption{mode: EncryptionSSECOS}
+	}
+}
+
+// WithSSEKMS 使用 KMS 托管密钥对上传的文件加密（SSE-KMS）。keyId 为空时使用默认密钥，context 是 JSON 格式的加密上下文，可为空。
+func WithSSEKMS(keyId, context string) option {
+	return func(o *options) {
+		o.encryption = &serverSideEncryption{mode: EncryptionSSEKMS, kmsKeyId: keyId, kmsContext: context}
+	}
+}
+
+// WithSSEC 使用客户提供的 32 字节密钥对上传的文件加密（SSE-C）。下载、查询文件信息时也要使用相同的密钥，
+// 复制文件时源文件和目标文件都使用该密钥。密钥只能通过 HTTPS 发送，需要同时使用 WithHttps。
+func WithSSEC(key []byte) option {
+	return func(o *options) {
+		o.encryption = &serverSideEncryption{mode: EncryptionSSEC, customKey: key}
+	}
+}
+
+// WithMetadata 上传时设置文件的自定义元数据，以 x-cos-meta-* 头发送。
+func WithMetadata(metadata map[string]string) option {
+	return func(o *options) {
+		for k, v := range metadata {
+			o.setUploadHeader(metaHeaderPrefix+k, v)
+		}
+	}
+}
+
+// WithCopySourceHost 复制文件时，源文件所在存储桶的域名。默认是本存储桶。
+func WithCopySourceHost(host string) option {
+	return func(o *options) {
+		o.copySourceHost = host
+	}
+}
+
+// WithReplaceMetadata 复制文件时，不复制源文件的元数据，而是使用 WithContentType、WithMetadata 等设置的元数据。
+func WithReplaceMetadata() option {
+	return func(o *options) {
+		o.replaceMetadata = true
+	}
+}
+
+// WithVersionId 读取、下载、复制、删除文件的指定版本。用于开启了版本控制的存储桶，默认是最新版本。
+func WithVersionId(versionId string) option {
+	return func(o *options) {
+		o.versionId = versionId
+	}
+}
+
+// WithIfMatch 文件的 ETag 与 etag 相同时才执行请求，否则返回 ErrPreconditionFailed。
+// 用于上传、下载、查询、删除文件，上传时表示只覆盖未被他人修改过的文件，分片下载时每个分片都校验。
+func WithIfMatch(etag string) option {
+	return func(o *options) {
+		o.setConditionHeader("If-Match", etag)
+	}
+}
+
+// WithIfNoneMatch 文件的 ETag 与 etag 不同时才执行请求。下载、查询时不满足条件返回 ErrNotModified，
+// 上传时返回 ErrPreconditionFailed。上传时使用 "*" 表示文件不存在时才上传。
+func WithIfNoneMatch(etag string) option {
+	return func(o *options) {
+		o.setConditionHeader("If-None-Match", etag)
+	}
+}
+
+// WithIfModifiedSince 文件在 t 之后被修改过才执行请求，否则返回 ErrNotModified。用于下载、查询文件。
+func WithIfModifiedSince(t time.Time) option {
+	return func(o *options) {
+		o.setConditionHeader("If-Modified-Since", t.UTC().Format(http.TimeFormat))
+	}
+}
+
+// WithIfUnmodifiedSince 文件在 t 之后没有被修改过才执行请求，否则返回 ErrPreconditionFailed。
+func WithIfUnmodifiedSince(t time.Time) option {
+	return func(o *options) {
+		o.setConditionHeader("If-Unmodified-Since", t.UTC().Format(http.TimeFormat))
+	}
+}
+
+// WithDryRun 按前缀删除文件时只列举将被删除的文件，不实际删除。
+func WithDryRun() option {
+	return func(o *options) {
+		o.dryRun = true
+	}
+}
+
+// 设置上传时的 HTTP 头，不修改客户端共享的头。
+func (o *options) setUploadHeader(key, value string) {
+	header := o.uploadHeader.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set(key, value)
+	o.uploadHeader = header
+}
+
+// 设置条件请求的 HTTP 头，不修改客户端共享的头。
+func (o *options) setConditionHeader(key, value string) {
+	header := o.conditionHeader.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set(key, value)
+	o.conditionHeader = header
+}
+
+// 未设置或不合法的参数使用 def 中的值。
+func (o *options) suit(def *options) {
+	if o.partSize <= 0 {
+		o.partSize = def.partSize
+	}
+	o.partSize = suitPartSize(o.partSize)
+	if o.multiThreshold <= 0 {
+		o.multiThreshold = def.multiThreshold
+	}
+	if o.numRoutines <= 0 {
+		o.numRoutines = def.numRoutines
+	}
+	if o.authExpirationTime <= 0 {
+		o.authExpirationTime = def.authExpirationTime
+	}
+}
@@ -0,0 +1,48 @@
+/*
+ * Copyright (c) 2025 ivfzhou
+ * tencent-cos-object-api is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package cos
+
+import "time"
+
+// PostPolicy 表单上传的策略，限制浏览器可以上传的文件。
+type PostPolicy struct {
+	// Key 文件 ID 必须与此相同。为空时使用 KeyPrefix 限制。
+	Key string
+	// KeyPrefix 文件 ID 必须以此开头，表单的 key 字段默认为 KeyPrefix + "${filename}"。为空时不限制。
+	KeyPrefix string
+	// MinSize 文件大小的最小值，为 0 时不限制。
+	MinSize int64
+	// MaxSize 文件大小的最大值，为 0 时不限制文件大小。
+	MaxSize int64
+	// ContentType 文件的 MIME 类型必须与此相同，优先于 WithContentType。为空时不限制。
+	ContentType string
+	// ContentTypePrefix 文件的 MIME 类型必须以此开头，如 "image/"。为空时不限制。
+	ContentTypePrefix string
+	// Expiration 策略的有效期。为 0 时使用客户端的 AuthExpirationTime。
+	Expiration time.Duration
+}
+
+// PostForm 表单上传需要的地址和字段。
+type PostForm struct {
+	// Url 表单提交的地址，使用 POST 方法，编码为 multipart/form-data。
+	Url string
+	// Fields 表单字段，需原样提交，文件内容放在最后的 file 字段中。
+	// 未设置 ContentType 时，浏览器需提交 Content-Type 字段。
+	Fields map[string]string
+}
+
+// FormUploader 生成浏览器表单上传（POST Object）的签名。
+type FormUploader interface {
+	// GetPostForm 按策略生成表单字段。opts 设置的文件头、元数据、存储类型会作为表单字段，并加入策略条件。
+	GetPostForm(policy *PostPolicy, opts ...option) *PostForm
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright (c) 2025 ivfzhou
+ * tencent-cos-object-api is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package cos
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type postImpl struct {
+	*baseImpl
+}
+
+// GetPostForm 按策略生成表单字段。
+func (c *postImpl) GetPostForm(policy *PostPolicy, opts ...option) *PostForm {
+	c = c.with(opts)
+	if policy == nil {
+		policy = &PostPolicy{}
+	}
+	expiration := policy.Expiration
+	if expiration <= 0 {
+		expiration = c.authExpirationTime
+	}
+	keyTime, end := c.keyTime(expiration)
+	credential := c.credential()
+	fields := map[string]string{
+		"q-sign-algorithm": "sha1",
+		"q-ak":             credential.SecretId,
+		"q-key-time":       keyTime,
+	}
+
+	// 生成策略条件。
+	conditions := []any{
+		map[string]string{"q-sign-algorithm": "sha1"},
+		map[string]string{"q-ak": credential.SecretId},
+		map[string]string{"q-sign-time": keyTime},
+	}
+	if len(credential.SessionToken) > 0 {
+		fields[securityTokenHeader] = credential.SessionToken
+		conditions = append(conditions, map[string]string{securityTokenHeader: credential.SessionToken})
+	}
+	switch {
+	case len(policy.Key) > 0:
+		key := strings.TrimLeft(policy.Key, "/")
+		fields["key"] = key
+		conditions = append(conditions, map[string]string{"key": key})
+	case len(policy.KeyPrefix) > 0:
+		prefix := strings.TrimLeft(policy.KeyPrefix, "/")
+		fields["key"] = prefix + "${filename}"
+		conditions = append(conditions, []string{"starts-with", "$key", prefix})
+	default:
+		fields["key"] = "${filename}"
+	}
+	if policy.MinSize > 0 || policy.MaxSize > 0 {
+		maxSize := policy.MaxSize
+		if maxSize <= 0 {
+			maxSize = math.MaxInt64
+		}
+		conditions = append(conditions, []any{"content-length-range", policy.MinSize, maxSize})
+	}
+	if len(policy.ContentType) > 0 {
+		fields["Content-Type"] = policy.ContentType
+		conditions = append(conditions, map[string]string{"Content-Type": policy.ContentType})
+	} else if len(policy.ContentTypePrefix) > 0 {
+		conditions = append(conditions, []string{"starts-with", "$Content-Type", policy.ContentTypePrefix})
+	}
+	for k, v := range c.uploadHeader {
+		if len(v) <= 0 || len(policy.ContentType) > 0 && http.CanonicalHeaderKey(k) == "Content-Type" {
+			continue // 策略指定的 MIME 类型优先。
+		}
+		if strings.HasPrefix(strings.ToLower(k), "x-cos-") {
+			k = strings.ToLower(k)
+		}
+		fields[k] = v[0]
+		conditions = append(conditions, map[string]string{k: v[0]})
+	}
+
+	// 签名策略，策略的过期时间与签名的结束时间一致。
+	policyJson, _ := json.Marshal(map[string]any{
+		"expiration": end.UTC().Format("2006-01-02T15:04:05.000Z"),
+		"conditions": conditions,
+	})
+	fields["policy"] = base64.StdEncoding.EncodeToString(policyJson)
+	fields["q-signature"] = hmacSha1(hmacSha1(credential.SecretKey, keyTime), fmt.Sprintf("%x", sha1.Sum(policyJson)))
+
+	u := &url.URL{Scheme: "http", Host: c.requestHost(), Path: c.objectPath("")}
+	if c.tls {
+		u.Scheme = "https"
+	}
+	return &PostForm{Url: u.String(), Fields: fields}
+}
+
+// 使用调用参数覆盖客户端参数。
+func (c *postImpl) with(opts []option) *postImpl {
+	b := c.baseImpl.with(opts)
+	if b == c.baseImpl {
+		return c
+	}
+	return &postImpl{b}
+}
@@ -0,0 +1,119 @@
+/*
+ * Copyright (c) 2025 ivfzhou
+ * tencent-cos-object-api is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package cos_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	cos "gitee.com/ivfzhou/tencent-cos-object-api"
+)
+
+func TestGetPostForm(t *testing.T) {
+	t.Run("正常运行", func(t *testing.T) {
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttps())
+		form := client.GetPostForm(&cos.PostPolicy{
+			KeyPrefix:         "/upload/",
+			MinSize:           1,
+			MaxSize:           1024,
+			ContentTypePrefix: "image/",
+			Expiration:        time.Hour,
+		}, cos.WithMetadata(map[string]string{"owner": "ivfzhou"}))
+		if form.Url != "https://"+host+"/" {
+			t.Errorf("unexpected url: got %v", form.Url)
+		}
+		fields := form.Fields
+		if fields["key"] != "upload/${filename}" || fields["q-ak"] != appKey || fields["q-sign-algorithm"] != "sha1" ||
+			fields["x-cos-meta-owner"] != "ivfzhou" {
+			t.Errorf("unexpected fields: got %v", fields)
+		}
+
+		// 校验签名。
+		policyJson, err := base64.StdEncoding.DecodeString(fields["policy"])
+		if err != nil {
+			t.Fatalf("unexpected error: want nil, got %v", err)
+		}
+		keyTime := fields["q-key-time"]
+		hash := hmac.New(sha1.New, []byte(appSecret))
+		hash.Write([]byte(keyTime))
+		signKey := fmt.Sprintf("%x", hash.Sum(nil))
+		hash = hmac.New(sha1.New, []byte(signKey))
+		hash.Write([]byte(fmt.Sprintf("%x", sha1.Sum(policyJson))))
+		if sign := fmt.Sprintf("%x", hash.Sum(nil)); sign != fields["q-signature"] {
+			t.Errorf("unexpected signature: want %v, got %v", sign, fields["q-signature"])
+		}
+
+		// 校验策略。
+		var policy struct {
+			Expiration string `json:"expiration"`
+			Conditions []any  `json:"conditions"`
+		}
+		if err = json.Unmarshal(policyJson, &policy); err != nil {
+			t.Fatalf("unexpected error: want nil, got %v", err)
+		}
+		begin, end, _ := strings.Cut(keyTime, ";")
+		bt, _ := strconv.ParseInt(begin, 10, 64)
+		et, _ := strconv.ParseInt(end, 10, 64)
+		expiration, _ := time.Parse(time.RFC3339, policy.Expiration)
+		if et-bt != int64(time.Hour.Seconds()) || expiration.Unix() != et {
+			t.Errorf("unexpected expiration: got %v, %v", keyTime, policy.Expiration)
+		}
+		conditions, _ := json.Marshal(policy.Conditions)
+		for _, want := range []string{
+			`{"q-sign-time":"` + keyTime + `"}`,
+			`["starts-with","$key","upload/"]`,
+			`["content-length-range",1,1024]`,
+			`["starts-with","$Content-Type","image/"]`,
+			`{"x-cos-meta-owner":"ivfzhou"}`,
+		} {
+			if !strings.Contains(string(conditions), want) {
+				t.Errorf("unexpected conditions: want %v, got %s", want, conditions)
+			}
+		}
+	})
+
+	t.Run("指定文件", func(t *testing.T) {
+		client := cos.NewClient(host, appKey, appSecret)
+		form := client.GetPostForm(&cos.PostPolicy{Key: "a.png", ContentType: "image/png"})
+		if form.Url != "http://"+host+"/" || form.Fields["key"] != "a.png" || form.Fields["Content-Type"] != "image/png" {
+			t.Errorf("unexpected form: got %v", form)
+		}
+		policyJson, _ := base64.StdEncoding.DecodeString(form.Fields["policy"])
+		if !strings.Contains(string(policyJson), `{"key":"a.png"}`) ||
+			!strings.Contains(string(policyJson), `{"Content-Type":"image/png"}`) ||
+			strings.Contains(string(policyJson), "content-length-range") {
+			t.Errorf("unexpected policy: got %s", policyJson)
+		}
+	})
+
+	t.Run("策略优先", func(t *testing.T) {
+		client := cos.NewClient(host, appKey, appSecret)
+		form := client.GetPostForm(&cos.PostPolicy{ContentType: "image/png", MinSize: 10},
+			cos.WithContentType("text/plain"))
+		if form.Fields["Content-Type"] != "image/png" {
+			t.Errorf("unexpected content type: want image/png, got %v", form.Fields["Content-Type"])
+		}
+		policyJson, _ := base64.StdEncoding.DecodeString(form.Fields["policy"])
+		if strings.Count(string(policyJson), "Content-Type") != 1 ||
+			!strings.Contains(string(policyJson), `["content-length-range",10,9223372036854775807]`) {
+			t.Errorf("unexpected policy: got %s", policyJson)
+		}
+	})
+}
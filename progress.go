@@ -0,0 +1,245 @@
+/*
+ * Copyright (c) 2025 ivfzhou
+ * tencent-cos-object-api is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package cos
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// ProgressEvent 文件传输进度。
+type ProgressEvent struct {
+	// FileId 文件 ID。
+	FileId string
+	// TotalBytes 文件大小，未知时为 -1。
+	TotalBytes int64
+	// TransferredBytes 已传输的字节数。
+	TransferredBytes int64
+	// TotalParts 分片总数，未知时为 -1。非分片传输时为 1。
+	TotalParts int64
+	// CompletedParts 已完成的分片数。
+	CompletedParts int64
+	// FailedParts 失败的分片数。
+	FailedParts int64
+	// BytesPerSecond 当前吞吐量，单位字节每秒。
+	BytesPerSecond float64
+}
+
+// ProgressFunc 传输进度回调函数。会被多个协程调用，但不会同时调用。
+type ProgressFunc func(event ProgressEvent)
+
+// 计算吞吐量的时间窗口。
+const progressRateWindow = 500 * time.Millisecond
+
+var errSeekUnsupported = errors.New("reader does not support seek")
+
+// 传输进度统计。为 nil 时所有方法都不做任何事。
+type progress struct {
+	fn       ProgressFunc
+	lock     sync.Mutex
+	event    ProgressEvent
+	start    time.Time
+	winStart time.Time
+	winBytes int64
+}
+
+// 统计分片传输进度的读取流。
+type progressReader struct {
+	r io.Reader
+	p *progress
+	n int64
+}
+
+// 统计下载传输进度的读取流，读取结束时标记分片完成。
+type progressReadCloser struct {
+	io.ReadCloser
+	p    *progress
+	done bool
+}
+
+// 统计分片传输进度的写入流。
+type progressWriterAt struct {
+	wa io.WriterAt
+	p  *progress
+	n  int64
+}
+
+// 创建传输进度统计。fn 为 nil 时返回 nil。
+func newProgress(fn ProgressFunc, fileId string, totalBytes, totalParts int64) *progress {
+	if fn == nil {
+		return nil
+	}
+	now := time.Now()
+	return &progress{
+		fn: fn,
+		event: ProgressEvent{
+			FileId:     fileId,
+			TotalBytes: totalBytes,
+			TotalParts: totalParts,
+		},
+		start:    now,
+		winStart: now,
+	}
+}
+
+// 创建传输进度统计，按文件大小是否使用分片模式计算分片总数。
+func (c *baseImpl) newProgress(fileId string, size int64) *progress {
+	parts := int64(1)
+	if c.useMultipart(size) {
+		parts = (size + c.partSize - 1) / c.partSize
+	}
+	return newProgress(c.progress, fileId, size, parts)
+}
+
+// 增加已传输的字节数。
+func (p *progress) add(n int64) {
+	if p == nil || n == 0 {
+		return
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.event.TransferredBytes += n
+
+	// 按时间窗口计算吞吐量。
+	p.winBytes += n
+	now := time.Now()
+	if elapsed := now.Sub(p.winStart); elapsed >= progressRateWindow {
+		p.event.BytesPerSecond = float64(p.winBytes) / elapsed.Seconds()
+		p.winStart = now
+		p.winBytes = 0
+	} else if p.event.BytesPerSecond <= 0 {
+		if elapsed = now.Sub(p.start); elapsed > 0 {
+			p.event.BytesPerSecond = float64(p.event.TransferredBytes) / elapsed.Seconds()
+		}
+	}
+	p.fn(p.event)
+}
+
+// 跳过已传输过的分片，用于断点续传。
+func (p *progress) skipPart(n int64) {
+	if p == nil {
+		return
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.event.TransferredBytes += n
+	p.event.CompletedParts++
+}
+
+// 分片传输结束。分片失败时回退 rollback 个已统计的字节。
+func (p *progress) partDone(err error, rollback int64) {
+	if p == nil {
+		return
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if err != nil {
+		p.event.FailedParts++
+		p.event.TransferredBytes -= rollback
+	} else {
+		p.event.CompletedParts++
+	}
+	p.fn(p.event)
+}
+
+// 统计读取流的传输进度。p 为 nil 时原样返回。
+func (p *progress) reader(r io.Reader) io.Reader {
+	if p == nil {
+		return r
+	}
+	return &progressReader{r: r, p: p}
+}
+
+// 统计下载读取流的传输进度。p 为 nil 时原样返回。
+func (p *progress) readCloser(rc io.ReadCloser) io.ReadCloser {
+	if p == nil {
+		return rc
+	}
+	return &progressReadCloser{ReadCloser: rc, p: p}
+}
+
+// 统计写入流的传输进度。p 为 nil 时原样返回。
+func (p *progress) writerAt(wa io.WriterAt) io.WriterAt {
+	if p == nil {
+		return wa
+	}
+	return &progressWriterAt{wa: wa, p: p}
+}
+
+// 读取流上传结束，失败时回退已统计的字节。
+func (p *progress) readerDone(r io.Reader, err error) {
+	if p == nil {
+		return
+	}
+	var n int64
+	if pr, ok := r.(*progressReader); ok && err != nil {
+		n, pr.n = pr.n, 0
+	}
+	p.partDone(err, n)
+}
+
+// 分片写入结束，失败时回退已统计的字节。
+func (p *progress) writerAtDone(wa io.WriterAt, err error) {
+	if p == nil {
+		return
+	}
+	var n int64
+	if pw, ok := wa.(*progressWriterAt); ok && err != nil {
+		n, pw.n = pw.n, 0
+	}
+	p.partDone(err, n)
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	r.p.add(int64(n))
+	return n, err
+}
+
+// Seek 重试时回到起始位置，回退已统计的字节。
+func (r *progressReader) Seek(offset int64, whence int) (int64, error) {
+	seeker, ok := r.r.(io.Seeker)
+	if !ok {
+		return 0, errSeekUnsupported
+	}
+	pos, err := seeker.Seek(offset, whence)
+	if err == nil && (offset != 0 || whence != io.SeekCurrent) {
+		r.p.add(-r.n)
+		r.n = 0
+	}
+	return pos, err
+}
+
+func (r *progressReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.p.add(int64(n))
+	if err != nil && !r.done {
+		r.done = true
+		if errors.Is(err, io.EOF) {
+			r.p.partDone(nil, 0)
+		} else {
+			r.p.partDone(err, 0)
+		}
+	}
+	return n, err
+}
+
+func (w *progressWriterAt) WriteAt(p []byte, off int64) (int, error) {
+	n, err := w.wa.WriteAt(p, off)
+	w.n += int64(n)
+	w.p.add(int64(n))
+	return n, err
+}
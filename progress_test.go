@@ -0,0 +1,277 @@
+/*
+ * Copyright (c) 2025 ivfzhou
+ * tencent-cos-object-api is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package cos_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	cos "gitee.com/ivfzhou/tencent-cos-object-api"
+)
+
+func TestProgress(t *testing.T) {
+	partSize := int64(1024 * 1024)
+
+	t.Run("上传进度", func(t *testing.T) {
+		for _, much := range []bool{false, true} {
+			data := MakeBytesWithSize(rand.Intn(1024) + 1)
+			if much {
+				data = MakeBytesWithSize(int(partSize)*(rand.Intn(3)+3) + rand.Intn(1024) + 1)
+			}
+			fn := func(req *http.Request) (*http.Response, error) {
+				if req.Method == http.MethodPost && req.URL.Query().Has("uploads") {
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body: NewReader([]byte("<InitiateMultipartUploadResult><UploadId>upload id"+
+							"</UploadId></InitiateMultipartUploadResult>"), nil, nil, nil),
+					}, nil
+				}
+				if req.Method == http.MethodGet {
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       NewReader([]byte("<ListPartsResult></ListPartsResult>"), nil, nil, nil),
+					}, nil
+				}
+				if _, err := io.Copy(io.Discard, req.Body); err != nil {
+					t.Errorf("unexpected error: want nil, got %v", err)
+				}
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       NewReader(nil, nil, nil, nil),
+				}, nil
+			}
+			var events []cos.ProgressEvent
+			lock := sync.Mutex{}
+			client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)),
+				cos.WithPartSize(partSize), cos.WithMultiThreshold(2))
+			err := client.Upload(context.Background(), "ivfzhou_test_file", data,
+				cos.WithProgress(func(event cos.ProgressEvent) {
+					lock.Lock()
+					defer lock.Unlock()
+					events = append(events, event)
+				}))
+			if err != nil {
+				t.Errorf("unexpected error: want nil, got %v", err)
+			}
+			CheckProgressEvents(t, events, int64(len(data)), partSize, much)
+		}
+	})
+
+	t.Run("下载进度", func(t *testing.T) {
+		for _, much := range []bool{false, true} {
+			data := MakeBytesWithSize(rand.Intn(1024) + 1)
+			if much {
+				data = MakeBytesWithSize(int(partSize)*(rand.Intn(3)+3) + rand.Intn(1024) + 1)
+			}
+			fn := func(req *http.Request) (*http.Response, error) {
+				if req.Method == http.MethodHead {
+					return &http.Response{
+						StatusCode:    http.StatusOK,
+						ContentLength: int64(len(data)),
+						Body:          NewReader(nil, nil, nil, nil),
+					}, nil
+				}
+				begin, end := int64(0), int64(len(data)-1)
+				if v := req.Header.Get("Range"); len(v) > 0 {
+					if _, err := fmt.Sscanf(v, "bytes=%d-%d", &begin, &end); err != nil {
+						t.Errorf("unexpected error: want nil, got %v", err)
+					}
+				}
+				time.Sleep(time.Millisecond)
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       NewReader(data[begin:end+1], nil, nil, nil),
+				}, nil
+			}
+			var events []cos.ProgressEvent
+			lock := sync.Mutex{}
+			client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)),
+				cos.WithProgress(func(event cos.ProgressEvent) {
+					lock.Lock()
+					defer lock.Unlock()
+					events = append(events, event)
+				}))
+			buf := &bytes.Buffer{}
+			err := client.DownloadToWriter(context.Background(), "ivfzhou_test_file", buf,
+				cos.WithPartSize(partSize), cos.WithMultiThreshold(2))
+			if err != nil {
+				t.Errorf("unexpected error: want nil, got %v", err)
+			}
+			if !bytes.Equal(buf.Bytes(), data) {
+				t.Errorf("unexpected result: want %v, got %v", len(data), buf.Len())
+			}
+			CheckProgressEvents(t, events, int64(len(data)), partSize, much)
+		}
+	})
+
+	t.Run("下载失败回退", func(t *testing.T) {
+		data := MakeBytesWithSize(int(partSize)*3 + rand.Intn(1024) + 1)
+		fn := func(req *http.Request) (*http.Response, error) {
+			if req.Method == http.MethodHead {
+				return &http.Response{
+					StatusCode:    http.StatusOK,
+					ContentLength: int64(len(data)),
+					Body:          NewReader(nil, nil, nil, nil),
+				}, nil
+			}
+			begin, end := int64(0), int64(len(data)-1)
+			if _, err := fmt.Sscanf(req.Header.Get("Range"), "bytes=%d-%d", &begin, &end); err != nil {
+				t.Errorf("unexpected error: want nil, got %v", err)
+			}
+			var readErr error
+			if begin == partSize {
+				readErr = errors.New("expected error")
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       NewReader(data[begin:end+1], nil, nil, readErr),
+			}, nil
+		}
+		var last cos.ProgressEvent
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)),
+			cos.WithPartSize(partSize), cos.WithMultiThreshold(2), cos.WithNumRoutines(1),
+			cos.WithRetryPolicy(&cos.RetryPolicy{MaxAttempts: 1}),
+			cos.WithProgress(func(event cos.ProgressEvent) { last = event }))
+		err := client.DownloadToWriterAt(context.Background(), "ivfzhou_test_file",
+			NewWriterAt(func(p []byte, off int64) (int, error) { return len(p), nil }))
+		if err == nil {
+			t.Fatalf("unexpected error: want not nil, got %v", err)
+		}
+		if last.TransferredBytes != last.CompletedParts*partSize || last.FailedParts != 1 {
+			t.Errorf("unexpected event: want %v/%v/1, got %v/%v/%v", last.CompletedParts*partSize,
+				last.CompletedParts, last.TransferredBytes, last.CompletedParts, last.FailedParts)
+		}
+	})
+
+	t.Run("失败后不再回调", func(t *testing.T) {
+		var returned, abortCount int32
+		fn := func(req *http.Request) (*http.Response, error) {
+			query := req.URL.Query()
+			switch {
+			case req.Method == http.MethodPost && query.Has("uploads"):
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body: NewReader([]byte("<InitiateMultipartUploadResult><UploadId>upload id"+
+						"</UploadId></InitiateMultipartUploadResult>"), nil, nil, nil),
+				}, nil
+			case req.Method == http.MethodDelete:
+				atomic.AddInt32(&abortCount, 1)
+			case req.Method == http.MethodPut:
+				time.Sleep(50 * time.Millisecond)
+				if _, err := io.Copy(io.Discard, req.Body); err != nil {
+					t.Errorf("unexpected error: want nil, got %v", err)
+				}
+			case req.Method == http.MethodHead:
+				return &http.Response{
+					StatusCode:    http.StatusOK,
+					ContentLength: partSize * 6,
+					Body:          NewReader(nil, nil, nil, nil),
+				}, nil
+			case req.Method == http.MethodGet:
+				begin, end := int64(0), int64(0)
+				if _, err := fmt.Sscanf(req.Header.Get("Range"), "bytes=%d-%d", &begin, &end); err != nil {
+					t.Errorf("unexpected error: want nil, got %v", err)
+				}
+				if begin == 0 {
+					return &http.Response{StatusCode: http.StatusInternalServerError, Body: NewReader(nil, nil, nil, nil)},
+						nil
+				}
+				time.Sleep(50 * time.Millisecond)
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       NewReader(make([]byte, end-begin+1), nil, nil, nil),
+				}, nil
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: NewReader(nil, nil, nil, nil)}, nil
+		}
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)),
+			cos.WithPartSize(partSize), cos.WithMultiThreshold(2), cos.WithNumRoutines(4),
+			cos.WithRetryPolicy(&cos.RetryPolicy{MaxAttempts: 1}), cos.WithNonCheckCrc64(),
+			cos.WithProgress(func(event cos.ProgressEvent) {
+				if atomic.LoadInt32(&returned) != 0 {
+					t.Errorf("unexpected event after return: %v", event)
+				}
+			}))
+
+		r := io.MultiReader(bytes.NewReader(MakeBytesWithSize(int(partSize)*3)),
+			iotest.ErrReader(errors.New("expected error")))
+		err := client.UploadFromReaderWithSize(context.Background(), "ivfzhou_test_file", partSize*6, r)
+		atomic.StoreInt32(&returned, 1)
+		if err == nil {
+			t.Errorf("unexpected error: want not nil, got %v", err)
+		}
+		time.Sleep(100 * time.Millisecond)
+		if n := atomic.LoadInt32(&abortCount); n != 1 {
+			t.Errorf("unexpected abort count: want 1, got %v", n)
+		}
+
+		atomic.StoreInt32(&returned, 0)
+		err = client.DownloadToWriterAt(context.Background(), "ivfzhou_test_file",
+			NewWriterAt(func(p []byte, off int64) (int, error) { return len(p), nil }))
+		atomic.StoreInt32(&returned, 1)
+		if err == nil {
+			t.Errorf("unexpected error: want not nil, got %v", err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	})
+}
+
+func CheckProgressEvents(t *testing.T, events []cos.ProgressEvent, size, partSize int64, much bool) {
+	t.Helper()
+	if len(events) <= 0 {
+		t.Fatalf("unexpected events: want not empty, got %v", len(events))
+	}
+	wantParts := int64(1)
+	if much {
+		wantParts = (size + partSize - 1) / partSize
+	}
+	prev := int64(0)
+	for _, v := range events {
+		if v.FileId != "ivfzhou_test_file" {
+			t.Errorf("unexpected file id: want ivfzhou_test_file, got %v", v.FileId)
+		}
+		if v.TotalBytes != size {
+			t.Errorf("unexpected total bytes: want %v, got %v", size, v.TotalBytes)
+		}
+		if v.TotalParts != wantParts {
+			t.Errorf("unexpected total parts: want %v, got %v", wantParts, v.TotalParts)
+		}
+		if v.TransferredBytes < prev || v.TransferredBytes > size {
+			t.Errorf("unexpected transferred bytes: prev %v, got %v", prev, v.TransferredBytes)
+		}
+		if v.BytesPerSecond < 0 {
+			t.Errorf("unexpected bytes per second: got %v", v.BytesPerSecond)
+		}
+		prev = v.TransferredBytes
+	}
+	last := events[len(events)-1]
+	if last.TransferredBytes != size {
+		t.Errorf("unexpected transferred bytes: want %v, got %v", size, last.TransferredBytes)
+	}
+	if last.CompletedParts != wantParts {
+		t.Errorf("unexpected completed parts: want %v, got %v", wantParts, last.CompletedParts)
+	}
+	if last.FailedParts != 0 {
+		t.Errorf("unexpected failed parts: want 0, got %v", last.FailedParts)
+	}
+}
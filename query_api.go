@@ -14,6 +14,7 @@ package cos
 
 import (
 	"context"
+	"iter"
 	"time"
 )
 
@@ -26,6 +27,32 @@ type File struct {
 	EntityTag string
 	// UploadTime 对象的最近一次上传的时间。
 	UploadTime time.Time
+	// StorageClass 存储类型。
+	StorageClass StorageClass
+	// Restoring 归档文件是否正在回热。
+	Restoring bool
+	// RestoreExpireTime 归档文件回热出的临时副本的过期时间，没有回热时为零值。
+	RestoreExpireTime time.Time
+	// Owner 文件的所有者。
+	Owner Owner
+}
+
+// Owner 文件所有者。
+type Owner struct {
+	// ID 所有者的 ID。
+	ID string
+	// DisplayName 所有者的名称。
+	DisplayName string
+}
+
+// ListResult 一页文件列表。
+type ListResult struct {
+	// Files 文件列表。
+	Files []*File
+	// Prefixes 子目录前缀列表，以分隔符结尾。不使用分隔符时为空。
+	Prefixes []string
+	// NextOffset 下一页的起点，为空时表示没有下一页。
+	NextOffset string
 }
 
 // FileInfo 文件信息。
@@ -40,16 +67,92 @@ type FileInfo struct {
 	UploadTime time.Time
 	// ExpireTime 过期时间。
 	ExpireTime time.Time
+	// ContentType 文件的 MIME 类型。
+	ContentType string
+	// ContentDisposition 文件的 Content-Disposition。
+	ContentDisposition string
+	// CacheControl 文件的 Cache-Control。
+	CacheControl string
+	// ContentEncoding 文件的 Content-Encoding。
+	ContentEncoding string
+	// Metadata 自定义元数据，键为去掉 x-cos-meta- 前缀后的小写名称。
+	Metadata map[string]string
+	// StorageClass 存储类型。
+	StorageClass StorageClass
+	// Restoring 归档文件是否正在回热。
+	Restoring bool
+	// RestoreExpireTime 归档文件回热出的临时副本的过期时间，没有回热时为零值。
+	RestoreExpireTime time.Time
+	// Encryption 服务端加密方式。
+	Encryption EncryptionMode
+	// KMSKeyId 使用 KMS 托管密钥加密时的密钥 ID。
+	KMSKeyId string
+	// VersionId 文件的版本 ID，存储桶没有开启版本控制时为空。
+	VersionId string
 }
 
+// FileVersion 文件版本。
+type FileVersion struct {
+	// ID 文件 ID。
+	ID string
+	// VersionId 版本 ID。
+	VersionId string
+	// IsLatest 是否是最新版本。
+	IsLatest bool
+	// IsDeleteMarker 是否是删除标记。删除标记没有大小、ETag 和存储类型。
+	IsDeleteMarker bool
+	// Size 文件大小。
+	Size int64
+	// EntityTag 对象被创建时标识对象内容的信息标签。
+	EntityTag string
+	// UploadTime 版本的创建时间。
+	UploadTime time.Time
+	// StorageClass 存储类型。
+	StorageClass StorageClass
+	// Owner 文件的所有者。
+	Owner Owner
+}
+
+// VersionListResult 一页文件版本列表。
+type VersionListResult struct {
+	// Versions 文件版本和删除标记，按文件 ID 升序、同一文件按创建时间降序排列。
+	Versions []*FileVersion
+	// Prefixes 子目录前缀列表，以分隔符结尾。不使用分隔符时为空。
+	Prefixes []string
+	// NextKeyOffset、NextVersionIdOffset 下一页的起点，NextKeyOffset 为空时表示没有下一页。
+	NextKeyOffset, NextVersionIdOffset string
+}
+
+// Readable 文件是否可以读取。归档存储的文件回热完成后才能读取。
+func (i *FileInfo) Readable() bool {
+	return !i.StorageClass.archived() || (!i.Restoring && !i.RestoreExpireTime.IsZero())
+}
+
+// Querier 查询文件。Info、Exist 可使用 WithVersionId 查询文件的指定版本。
 type Querier interface {
-	// Info 获取文件信息。
-	Info(ctx context.Context, fileId string) (*FileInfo, error)
+	// Info 获取文件信息。文件使用客户提供的密钥加密时，需要 WithSSEC 设置相同的密钥。
+	Info(ctx context.Context, fileId string, opts ...option) (*FileInfo, error)
 
-	// Exist 文件是否存在。
-	Exist(ctx context.Context, fileId string) (bool, error)
+	// Exist 文件是否存在。文件使用客户提供的密钥加密时，需要 WithSSEC 设置相同的密钥。
+	Exist(ctx context.Context, fileId string, opts ...option) (bool, error)
 
 	// ListFiles 获取文件列表信息列表。
 	ListFiles(ctx context.Context, dir, fileNamePrefix, offset string, limit int64) (
 		files []*File, nextOffset string, err error)
+
+	// List 列举前缀 prefix 下的文件和子目录。delimiter 一般为 "/"，为空时列举前缀下所有层级的文件，不返回子目录。
+	// offset 为上一页的 NextOffset，limit 最大为一千。
+	List(ctx context.Context, prefix, delimiter, offset string, limit int64) (*ListResult, error)
+
+	// ListFileVersions 列举前缀 prefix 下的文件版本和删除标记。delimiter 为空时列举前缀下所有层级的文件。
+	// keyOffset、versionIdOffset 为上一页的 NextKeyOffset、NextVersionIdOffset，limit 最大为一千。
+	ListFileVersions(ctx context.Context, prefix, delimiter, keyOffset, versionIdOffset string, limit int64) (
+		*VersionListResult, error)
+
+	// AllFiles 遍历目录下的文件，与 ListFiles 一样不包含子目录中的文件。自动翻页。
+	// 列举失败或上下文终止时产出错误并结束遍历，调用方中断遍历时不再请求下一页。
+	AllFiles(ctx context.Context, dir, fileNamePrefix string) iter.Seq2[*File, error]
+
+	// AllFilesRecursive 遍历前缀 prefix 下所有层级的文件。自动翻页，结束条件与 AllFiles 相同。
+	AllFilesRecursive(ctx context.Context, prefix string) iter.Seq2[*File, error]
 }
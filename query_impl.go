@@ -17,6 +17,7 @@ import (
 	"encoding/xml"
 	"errors"
 	"io"
+	"iter"
 	"net/http"
 	"net/url"
 	"path/filepath"
@@ -30,11 +31,12 @@ type queryImpl struct {
 }
 
 // Info 获取文件信息。
-func (c *queryImpl) Info(ctx context.Context, fileId string) (*FileInfo, error) {
+func (c *queryImpl) Info(ctx context.Context, fileId string, opts ...option) (*FileInfo, error) {
 	fileId = suitFileId(fileId)
 	if len(fileId) <= 0 {
 		return nil, errors.New("fileId is invalid")
 	}
+	c = c.with(opts)
 
 	// 发送 HTTP 请求。
 	rsp, err := c.head(ctx, fileId)
@@ -43,25 +45,16 @@ func (c *queryImpl) Info(ctx context.Context, fileId string) (*FileInfo, error)
 	}
 
 	// 解析响应。
-	size, err := strconv.ParseInt(rsp.Header.Get("Content-Length"), 10, 64)
-	printError(err)
-	info := &FileInfo{
-		Size:      size,
-		EntityTag: rsp.Header.Get("Etag"),
-		Crc64:     rsp.Header.Get("x-cos-hash-crc64ecma"),
-	}
-	info.UploadTime, _ = time.ParseInLocation(time.RFC1123, rsp.Header.Get("Last-Modified"), time.Local)
-	info.ExpireTime, _ = time.ParseInLocation(time.RFC1123, rsp.Header.Get("Expires"), time.Local)
-
-	return info, nil
+	return c.parseFileInfo(rsp.Header), nil
 }
 
 // Exist 文件是否存在。
-func (c *queryImpl) Exist(ctx context.Context, fileId string) (bool, error) {
+func (c *queryImpl) Exist(ctx context.Context, fileId string, opts ...option) (bool, error) {
 	fileId = suitFileId(fileId)
 	if len(fileId) <= 0 {
 		return false, errors.New("fileId is invalid")
 	}
+	c = c.with(opts)
 
 	// 发送 HTTP 请求。
 	_, err := c.head(ctx, fileId)
@@ -79,7 +72,148 @@ func (c *queryImpl) Exist(ctx context.Context, fileId string) (bool, error) {
 func (c *queryImpl) ListFiles(ctx context.Context, dir, fileNamePrefix, offset string, limit int64) (
 	files []*File, nextOffset string, err error) {
 
-	// 生成文件路径。
+	return c.listFiles(ctx, listPrefix(dir, fileNamePrefix), "/", offset, limit)
+}
+
+// List 列举前缀下的文件和子目录。
+func (c *queryImpl) List(ctx context.Context, prefix, delimiter, offset string, limit int64) (*ListResult, error) {
+	return c.list(ctx, strings.TrimLeft(prefix, "/"), delimiter, offset, limit)
+}
+
+// ListFileVersions 列举前缀下的文件版本和删除标记。
+func (c *queryImpl) ListFileVersions(ctx context.Context, prefix, delimiter, keyOffset, versionIdOffset string,
+	limit int64) (*VersionListResult, error) {
+
+	// 创建请求体。
+	query := url.Values{}
+	query.Set("versions", "")
+	query.Set("prefix", strings.TrimLeft(prefix, "/"))
+	if len(delimiter) > 0 {
+		query.Set("delimiter", delimiter)
+	}
+	if limit > 0 {
+		query.Set("max-keys", strconv.FormatInt(limit, 10))
+	}
+	if len(keyOffset) > 0 {
+		query.Set("key-marker", keyOffset)
+		if len(versionIdOffset) > 0 {
+			query.Set("version-id-marker", versionIdOffset)
+		}
+	}
+	req := c.genReq(http.MethodGet, "", query, nil, nil)
+
+	// 发送 HTTP 请求。
+	rsp, err := c.sendHttp(ctx, OperationListObjectVersions, req)
+	if err != nil {
+		return nil, err
+	}
+	rspBody, err := io.ReadAll(rsp.Body)
+	c.closeRsp(rsp)
+	if err != nil {
+		return nil, err
+	}
+
+	// 解析响应体，版本和删除标记交错出现，需保持顺序。
+	type Version struct {
+		XMLName      xml.Name
+		Key          string
+		VersionId    string
+		IsLatest     bool
+		LastModified string
+		ETag         string
+		Size         int64
+		StorageClass string
+		Owner        struct {
+			ID          string
+			DisplayName string
+		}
+	}
+	type CommonPrefixes struct {
+		Prefix string
+	}
+	type ListVersionsResult struct {
+		IsTruncated         bool
+		NextKeyMarker       string
+		NextVersionIdMarker string
+		CommonPrefixes      []CommonPrefixes
+		Versions            []Version `xml:",any"`
+	}
+	var res ListVersionsResult
+	if err = xml.Unmarshal(rspBody, &res); err != nil {
+		return nil, err
+	}
+
+	// 组装版本信息。
+	result := &VersionListResult{Prefixes: make([]string, len(res.CommonPrefixes))}
+	for _, v := range res.Versions {
+		if v.XMLName.Local != "Version" && v.XMLName.Local != "DeleteMarker" {
+			continue
+		}
+		version := &FileVersion{
+			ID:             v.Key,
+			VersionId:      v.VersionId,
+			IsLatest:       v.IsLatest,
+			IsDeleteMarker: v.XMLName.Local == "DeleteMarker",
+			Size:           v.Size,
+			EntityTag:      v.ETag,
+			Owner:          Owner{ID: v.Owner.ID, DisplayName: v.Owner.DisplayName},
+		}
+		version.UploadTime, _ = time.Parse(time.RFC3339, v.LastModified)
+		if !version.IsDeleteMarker {
+			version.StorageClass = suitStorageClass(v.StorageClass)
+		}
+		result.Versions = append(result.Versions, version)
+	}
+	for i, v := range res.CommonPrefixes {
+		result.Prefixes[i] = v.Prefix
+	}
+	if res.IsTruncated {
+		result.NextKeyOffset, result.NextVersionIdOffset = res.NextKeyMarker, res.NextVersionIdMarker
+	}
+
+	return result, nil
+}
+
+// AllFiles 遍历目录下的文件。
+func (c *queryImpl) AllFiles(ctx context.Context, dir, fileNamePrefix string) iter.Seq2[*File, error] {
+	return c.allFiles(ctx, listPrefix(dir, fileNamePrefix), "/")
+}
+
+// AllFilesRecursive 遍历前缀下所有层级的文件。
+func (c *queryImpl) AllFilesRecursive(ctx context.Context, prefix string) iter.Seq2[*File, error] {
+	return c.allFiles(ctx, strings.TrimLeft(prefix, "/"), "")
+}
+
+// 逐页列举前缀为 prefix 的文件。
+func (c *baseImpl) allFiles(ctx context.Context, prefix, delimiter string) iter.Seq2[*File, error] {
+	return func(yield func(*File, error) bool) {
+		for marker := ""; ; {
+			files, nextMarker, err := c.listFiles(ctx, prefix, delimiter, marker, 1000)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, v := range files {
+				if err = ctx.Err(); err != nil {
+					yield(nil, err)
+					return
+				}
+				if !yield(v, nil) {
+					return
+				}
+			}
+
+			// 没有下一页了。
+			if len(nextMarker) <= 0 {
+				return
+			}
+			marker = nextMarker
+		}
+	}
+}
+
+// 生成目录下文件的列举前缀。没有文件名前缀时列举整个目录。
+func listPrefix(dir, fileNamePrefix string) string {
 	dir = filepath.Clean(dir)
 	fileNamePrefix = filepath.Clean(fileNamePrefix)
 	if strings.Contains(fileNamePrefix, "/") {
@@ -87,59 +221,176 @@ func (c *queryImpl) ListFiles(ctx context.Context, dir, fileNamePrefix, offset s
 		dir = filepath.Join(dir, fileNamePrefix[:index])
 		fileNamePrefix = fileNamePrefix[index:]
 	}
-	fileId := filepath.Join(dir, fileNamePrefix)
+	prefix := strings.TrimLeft(filepath.Join(dir, fileNamePrefix), "/")
+	if fileNamePrefix != "." {
+		return prefix
+	}
+	if prefix == "." || len(prefix) <= 0 {
+		return ""
+	}
+	return prefix + "/"
+}
+
+// 列举前缀为 prefix 的文件。delimiter 为空时列举前缀下所有层级的文件。
+func (c *baseImpl) listFiles(ctx context.Context, prefix, delimiter, marker string, limit int64) (
+	files []*File, nextMarker string, err error) {
 
+	res, err := c.list(ctx, prefix, delimiter, marker, limit)
+	if err != nil {
+		return
+	}
+	return res.Files, res.NextOffset, nil
+}
+
+// 列举前缀为 prefix 的文件和子目录。
+func (c *baseImpl) list(ctx context.Context, prefix, delimiter, marker string, limit int64) (*ListResult, error) {
 	// 创建请求体。
 	query := url.Values{}
-	query.Set("prefix", fileId)
-	query.Set("delimiter", "/")
+	query.Set("prefix", prefix)
+	if len(delimiter) > 0 {
+		query.Set("delimiter", delimiter)
+	}
 	if limit > 0 {
 		query.Set("max-keys", strconv.FormatInt(limit, 10))
 	}
-	if len(offset) > 0 {
-		query.Set("marker", offset)
+	if len(marker) > 0 {
+		query.Set("marker", marker)
 	}
 	req := c.genReq(http.MethodGet, "", query, nil, nil)
 
 	// 发送 HTTP 请求。
-	rsp, err := c.sendHttp(ctx, req)
+	rsp, err := c.sendHttp(ctx, OperationGetBucket, req)
 	if err != nil {
-		return
+		return nil, err
 	}
 	rspBody, err := io.ReadAll(rsp.Body)
-	closeRsp(rsp)
+	c.closeRsp(rsp)
 	if err != nil {
-		return
+		return nil, err
 	}
 
 	// 解析响应体。
 	type Contents struct {
-		Key          string
-		LastModified string
-		ETag         string
-		Size         int64
+		Key           string
+		LastModified  string
+		ETag          string
+		Size          int64
+		StorageClass  string
+		RestoreStatus struct {
+			IsRestoreInProgress bool
+			RestoreExpiryDate   string
+		}
+		Owner struct {
+			ID          string
+			DisplayName string
+		}
+	}
+	type CommonPrefixes struct {
+		Prefix string
 	}
 	type ListBucketResult struct {
-		NextMarker string
-		Contents   []Contents
+		IsTruncated    bool
+		NextMarker     string
+		Contents       []Contents
+		CommonPrefixes []CommonPrefixes
 	}
 	var res ListBucketResult
 	if err = xml.Unmarshal(rspBody, &res); err != nil {
-		return
+		return nil, err
 	}
 
 	// 组装文件信息。
-	nextOffset = res.NextMarker
-	files = make([]*File, len(res.Contents))
+	result := &ListResult{
+		Files:      make([]*File, len(res.Contents)),
+		Prefixes:   make([]string, len(res.CommonPrefixes)),
+		NextOffset: res.NextMarker,
+	}
 	for i, v := range res.Contents {
 		mt, _ := time.Parse(time.RFC3339, v.LastModified)
-		files[i] = &File{
-			ID:         v.Key,
-			Size:       v.Size,
-			EntityTag:  v.ETag,
-			UploadTime: mt,
+		result.Files[i] = &File{
+			ID:           v.Key,
+			Size:         v.Size,
+			EntityTag:    v.ETag,
+			UploadTime:   mt,
+			StorageClass: suitStorageClass(v.StorageClass),
+			Restoring:    v.RestoreStatus.IsRestoreInProgress,
+			Owner:        Owner{ID: v.Owner.ID, DisplayName: v.Owner.DisplayName},
 		}
+		result.Files[i].RestoreExpireTime, _ = time.Parse(time.RFC3339, v.RestoreStatus.RestoreExpiryDate)
+	}
+	for i, v := range res.CommonPrefixes {
+		result.Prefixes[i] = v.Prefix
 	}
 
+	// 没有返回 NextMarker 时，以最后一个文件或子目录作为下一页的起点。
+	if res.IsTruncated && len(result.NextOffset) <= 0 {
+		if len(result.Files) > 0 {
+			result.NextOffset = result.Files[len(result.Files)-1].ID
+		}
+		if len(result.Prefixes) > 0 {
+			result.NextOffset = max(result.NextOffset, result.Prefixes[len(result.Prefixes)-1])
+		}
+	}
+
+	return result, nil
+}
+
+// 使用调用参数覆盖客户端参数。
+func (c *queryImpl) with(opts []option) *queryImpl {
+	b := c.baseImpl.with(opts)
+	if b == c.baseImpl {
+		return c
+	}
+	return &queryImpl{b}
+}
+
+// 从 HEAD 响应头中解析文件信息。
+func (c *baseImpl) parseFileInfo(header http.Header) *FileInfo {
+	size, err := strconv.ParseInt(header.Get("Content-Length"), 10, 64)
+	c.logError(context.Background(), "parse content length failed", err)
+	info := &FileInfo{
+		Size:               size,
+		EntityTag:          header.Get("Etag"),
+		Crc64:              header.Get("x-cos-hash-crc64ecma"),
+		ContentType:        header.Get("Content-Type"),
+		ContentDisposition: header.Get("Content-Disposition"),
+		CacheControl:       header.Get("Cache-Control"),
+		ContentEncoding:    header.Get("Content-Encoding"),
+		Metadata:           parseMetadata(header),
+		StorageClass:       suitStorageClass(header.Get("x-cos-storage-class")),
+		VersionId:          header.Get("x-cos-version-id"),
+	}
+	info.UploadTime, _ = time.ParseInLocation(time.RFC1123, header.Get("Last-Modified"), time.Local)
+	info.ExpireTime, _ = time.ParseInLocation(time.RFC1123, header.Get("Expires"), time.Local)
+	info.Restoring, info.RestoreExpireTime = parseRestore(header.Get("x-cos-restore"))
+	info.Encryption, info.KMSKeyId = parseEncryption(header)
+	return info
+}
+
+// 服务端不返回标准存储的存储类型。
+func suitStorageClass(class string) StorageClass {
+	if len(class) <= 0 {
+		return StorageClassStandard
+	}
+	return StorageClass(class)
+}
+
+// 解析回热状态，格式如 ongoing-request="false", expiry-date="Wed, 27 Jul 2022 13:02:03 GMT"。
+func parseRestore(value string) (restoring bool, expireTime time.Time) {
+	for len(value) > 0 {
+		var pair string
+		pair, value = value, ""
+		if i := strings.Index(pair, `",`); i >= 0 {
+			pair, value = pair[:i+1], pair[i+2:]
+		}
+		k, v, _ := strings.Cut(strings.TrimSpace(pair), "=")
+		v = strings.Trim(v, `"`)
+		switch strings.ToLower(k) {
+		case "ongoing-request":
+			restoring = v == "true"
+		case "expiry-date":
+			expireTime, _ = time.Parse(http.TimeFormat, v)
+		}
+	}
 	return
 }
@@ -16,8 +16,10 @@ import (
 	"context"
 	"encoding/xml"
 	"errors"
+	"maps"
 	"math/rand"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"sync/atomic"
@@ -88,6 +90,102 @@ func TestInfo(t *testing.T) {
 		}
 	})
 
+	t.Run("文件元数据", func(t *testing.T) {
+		fileId := "/ivfzhou_test_file"
+		atomic.StoreInt32(&CloseCount, 0)
+		fn := func(req *http.Request) (*http.Response, error) {
+			header := http.Header{
+				"Content-Length":      []string{"10"},
+				"Content-Type":        []string{"text/plain"},
+				"Content-Disposition": []string{"attachment; filename=a.txt"},
+				"Cache-Control":       []string{"max-age=3600"},
+				"Content-Encoding":    []string{"gzip"},
+			}
+			header.Set("x-cos-meta-Owner", "ivfzhou")
+			header.Set("x-cos-meta-project", "cos")
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     header,
+				Body:       NewReader(nil, nil, nil, nil),
+			}, nil
+		}
+		info, err := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn))).
+			Info(context.Background(), fileId)
+		if err != nil {
+			t.Fatalf("unexpected error: want nil, got %v", err)
+		}
+		if info.ContentType != "text/plain" {
+			t.Errorf("unexpected content type: want text/plain, got %v", info.ContentType)
+		}
+		if info.ContentDisposition != "attachment; filename=a.txt" {
+			t.Errorf("unexpected content disposition: want attachment; filename=a.txt, got %v",
+				info.ContentDisposition)
+		}
+		if info.CacheControl != "max-age=3600" {
+			t.Errorf("unexpected cache control: want max-age=3600, got %v", info.CacheControl)
+		}
+		if info.ContentEncoding != "gzip" {
+			t.Errorf("unexpected content encoding: want gzip, got %v", info.ContentEncoding)
+		}
+		want := map[string]string{"owner": "ivfzhou", "project": "cos"}
+		if !maps.Equal(info.Metadata, want) {
+			t.Errorf("unexpected metadata: want %v, got %v", want, info.Metadata)
+		}
+		if closeCount := atomic.LoadInt32(&CloseCount); closeCount != 0 {
+			t.Errorf("expected close count: want 0, got %v", closeCount)
+		}
+	})
+
+	t.Run("存储类型", func(t *testing.T) {
+		cases := []struct {
+			class, restore string
+			want           cos.StorageClass
+			restoring      bool
+			expireTime     string
+			readable       bool
+		}{
+			{"", "", cos.StorageClassStandard, false, "", true},
+			{"STANDARD_IA", "", cos.StorageClassStandardIA, false, "", true},
+			{"ARCHIVE", "", cos.StorageClassArchive, false, "", false},
+			{"ARCHIVE", `ongoing-request="true"`, cos.StorageClassArchive, true, "", false},
+			{"DEEP_ARCHIVE", `ongoing-request="false", expiry-date="Wed, 27 Jul 2022 13:02:03 GMT"`,
+				cos.StorageClassDeepArchive, false, "Wed, 27 Jul 2022 13:02:03 GMT", true},
+		}
+		for _, v := range cases {
+			fn := func(req *http.Request) (*http.Response, error) {
+				header := http.Header{}
+				if len(v.class) > 0 {
+					header.Set("x-cos-storage-class", v.class)
+				}
+				if len(v.restore) > 0 {
+					header.Set("x-cos-restore", v.restore)
+				}
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Header:     header,
+					Body:       NewReader(nil, nil, nil, nil),
+				}, nil
+			}
+			info, err := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn))).
+				Info(context.Background(), "/ivfzhou_test_file")
+			if err != nil {
+				t.Fatalf("unexpected error: want nil, got %v", err)
+			}
+			if info.StorageClass != v.want {
+				t.Errorf("unexpected storage class: want %v, got %v", v.want, info.StorageClass)
+			}
+			if info.Restoring != v.restoring {
+				t.Errorf("unexpected restoring: want %v, got %v", v.restoring, info.Restoring)
+			}
+			if len(v.expireTime) > 0 && info.RestoreExpireTime.UTC().Format(http.TimeFormat) != v.expireTime {
+				t.Errorf("unexpected restore expire time: want %v, got %v", v.expireTime, info.RestoreExpireTime)
+			}
+			if info.Readable() != v.readable {
+				t.Errorf("unexpected readable: want %v, got %v", v.readable, info.Readable())
+			}
+		}
+	})
+
 	t.Run("响应失败", func(t *testing.T) {
 		for range 100 {
 			fileId := "/ivfzhou_test_file"
@@ -416,3 +514,162 @@ func TestListFiles(t *testing.T) {
 		}
 	}
 }
+
+func TestAllFiles(t *testing.T) {
+	keys := make([]string, 0, 2600)
+	for i := range 2500 {
+		keys = append(keys, "dir/"+strconv.Itoa(i))
+	}
+	for i := range 100 {
+		keys = append(keys, "dir/sub/"+strconv.Itoa(i))
+	}
+	bucket := NewMockBucket(append(keys, "dir.txt", "other/1")...)
+	client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(bucket.Handle)))
+
+	t.Run("遍历目录", func(t *testing.T) {
+		ids := make([]string, 0, 2500)
+		for f, err := range client.AllFiles(context.Background(), "/dir", "") {
+			if err != nil {
+				t.Fatalf("unexpected error: want nil, got %v", err)
+			}
+			ids = append(ids, f.ID)
+		}
+		if !slices.Equal(ids, slices.Sorted(slices.Values(keys[:2500]))) {
+			t.Errorf("unexpected files: want %v, got %v", 2500, len(ids))
+		}
+	})
+
+	t.Run("递归遍历", func(t *testing.T) {
+		ids := make([]string, 0, len(keys))
+		for f, err := range client.AllFilesRecursive(context.Background(), "dir/") {
+			if err != nil {
+				t.Fatalf("unexpected error: want nil, got %v", err)
+			}
+			ids = append(ids, f.ID)
+		}
+		if !slices.Equal(ids, slices.Sorted(slices.Values(keys))) {
+			t.Errorf("unexpected files: want %v, got %v", len(keys), len(ids))
+		}
+	})
+
+	t.Run("中断遍历", func(t *testing.T) {
+		count := int32(0)
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(
+			func(req *http.Request) (*http.Response, error) {
+				atomic.AddInt32(&count, 1)
+				return bucket.Handle(req)
+			})))
+		n := 0
+		for range client.AllFilesRecursive(context.Background(), "dir/") {
+			if n++; n >= 1500 {
+				break
+			}
+		}
+		if c := atomic.LoadInt32(&count); c != 2 {
+			t.Errorf("unexpected request count: want 2, got %v", c)
+		}
+	})
+
+	t.Run("上下文终止", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		n := 0
+		var err error
+		for _, err = range client.AllFilesRecursive(ctx, "dir/") {
+			if err != nil {
+				break
+			}
+			if n++; n == 10 {
+				cancel()
+			}
+		}
+		if !errors.Is(err, context.Canceled) || n != 10 {
+			t.Errorf("unexpected error: want %v, got %v, %v", context.Canceled, err, n)
+		}
+	})
+
+	t.Run("列举失败", func(t *testing.T) {
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(
+			func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusForbidden,
+					Body:       NewReader([]byte("<Error><Code>AccessDenied</Code></Error>"), nil, nil, nil),
+				}, nil
+			})))
+		var err error
+		for _, err = range client.AllFiles(context.Background(), "dir", "") {
+		}
+		if !errors.Is(err, cos.ErrAccessDenied) {
+			t.Errorf("unexpected error: want %v, got %v", cos.ErrAccessDenied, err)
+		}
+	})
+}
+
+func TestList(t *testing.T) {
+	t.Run("正常运行", func(t *testing.T) {
+		fn := func(req *http.Request) (*http.Response, error) {
+			query := req.URL.Query()
+			if query.Get("prefix") != "dir/" || query.Get("delimiter") != "/" || query.Get("max-keys") != "10" {
+				t.Errorf("unexpected query: got %v", query)
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: NewReader([]byte(`<ListBucketResult>
+<IsTruncated>true</IsTruncated><NextMarker>dir/b/</NextMarker>
+<Contents><Key>dir/1.txt</Key><Size>3</Size><StorageClass>STANDARD_IA</StorageClass>
+<Owner><ID>1250000000</ID><DisplayName>ivfzhou</DisplayName></Owner></Contents>
+<CommonPrefixes><Prefix>dir/a/</Prefix></CommonPrefixes>
+<CommonPrefixes><Prefix>dir/b/</Prefix></CommonPrefixes>
+</ListBucketResult>`), nil, nil, nil)}, nil
+		}
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)))
+		res, err := client.List(context.Background(), "/dir/", "/", "", 10)
+		if err != nil {
+			t.Fatalf("unexpected error: want nil, got %v", err)
+		}
+		if len(res.Files) != 1 || res.Files[0].ID != "dir/1.txt" || res.Files[0].Size != 3 {
+			t.Errorf("unexpected files: got %v", res.Files)
+		}
+		if f := res.Files[0]; f.StorageClass != cos.StorageClassStandardIA ||
+			f.Owner != (cos.Owner{ID: "1250000000", DisplayName: "ivfzhou"}) {
+			t.Errorf("unexpected file: got %+v", f)
+		}
+		if !slices.Equal(res.Prefixes, []string{"dir/a/", "dir/b/"}) {
+			t.Errorf("unexpected prefixes: got %v", res.Prefixes)
+		}
+		if res.NextOffset != "dir/b/" {
+			t.Errorf("unexpected next offset: want dir/b/, got %v", res.NextOffset)
+		}
+	})
+
+	t.Run("翻页", func(t *testing.T) {
+		keys := []string{"dir/1", "dir/2", "dir/a/1", "dir/a/2", "dir/b/1", "dir/c", "dir/d/1"}
+		bucket := NewMockBucket(keys...)
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(bucket.Handle)))
+		for _, c := range []struct {
+			delimiter string
+			files     []string
+			prefixes  []string
+		}{
+			{"/", []string{"dir/1", "dir/2", "dir/c"}, []string{"dir/a/", "dir/b/", "dir/d/"}},
+			{"", keys, nil},
+		} {
+			var files, prefixes []string
+			offset := ""
+			for {
+				res, err := client.List(context.Background(), "dir/", c.delimiter, offset, 2)
+				if err != nil {
+					t.Fatalf("unexpected error: want nil, got %v", err)
+				}
+				for _, v := range res.Files {
+					files = append(files, v.ID)
+				}
+				prefixes = append(prefixes, res.Prefixes...)
+				if offset = res.NextOffset; len(offset) <= 0 {
+					break
+				}
+			}
+			if !slices.Equal(files, c.files) || !slices.Equal(prefixes, c.prefixes) {
+				t.Errorf("unexpected result: want %v %v, got %v %v", c.files, c.prefixes, files, prefixes)
+			}
+		}
+	})
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright (c) 2025 ivfzhou
+ * tencent-cos-object-api is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package cos
+
+import (
+	"context"
+	"time"
+)
+
+// StorageClass 文件的存储类型。
+type StorageClass string
+
+const (
+	// StorageClassStandard 标准存储。
+	StorageClassStandard StorageClass = "STANDARD"
+	// StorageClassStandardIA 低频存储。
+	StorageClassStandardIA StorageClass = "STANDARD_IA"
+	// StorageClassIntelligentTiering 智能分层存储。
+	StorageClassIntelligentTiering StorageClass = "INTELLIGENT_TIERING"
+	// StorageClassArchive 归档存储，读取前需要回热。
+	StorageClassArchive StorageClass = "ARCHIVE"
+	// StorageClassDeepArchive 深度归档存储，读取前需要回热。
+	StorageClassDeepArchive StorageClass = "DEEP_ARCHIVE"
+)
+
+// 是否是需要回热才能读取的存储类型。
+func (s StorageClass) archived() bool {
+	return s == StorageClassArchive || s == StorageClassDeepArchive
+}
+
+// RestoreTier 回热归档文件的模式。
+type RestoreTier string
+
+const (
+	// RestoreTierExpedited 极速模式。深度归档存储不支持。
+	RestoreTierExpedited RestoreTier = "Expedited"
+	// RestoreTierStandard 标准模式。
+	RestoreTierStandard RestoreTier = "Standard"
+	// RestoreTierBulk 批量模式。
+	RestoreTierBulk RestoreTier = "Bulk"
+)
+
+type Restorer interface {
+	// Restore 回热归档文件。days 是回热出的临时副本保留的天数，tier 是回热模式，为空时使用标准模式。
+	// 文件已在回热中时返回 nil。
+	Restore(ctx context.Context, fileId string, days int, tier RestoreTier) error
+
+	// WaitRestored 每隔 interval 查询一次文件状态，直到文件可以读取或上下文终止。interval 小于等于零时每分钟查询一次。
+	// 文件是归档存储且没有在回热时，返回 ErrInvalidObjectState。
+	WaitRestored(ctx context.Context, fileId string, interval time.Duration) error
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright (c) 2025 ivfzhou
+ * tencent-cos-object-api is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package cos
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type restoreImpl struct {
+	*baseImpl
+}
+
+// Restore 回热归档文件。
+func (c *restoreImpl) Restore(ctx context.Context, fileId string, days int, tier RestoreTier) error {
+	fileId = suitFileId(fileId)
+	if len(fileId) <= 0 {
+		return errors.New("fileId is invalid")
+	}
+	if days <= 0 {
+		return errors.New("days is invalid")
+	}
+	if len(tier) <= 0 {
+		tier = RestoreTierStandard
+	}
+
+	// 组装请求体。
+	type CASJobParameters struct {
+		Tier RestoreTier
+	}
+	type RestoreRequest struct {
+		Days             int
+		CASJobParameters CASJobParameters
+	}
+	reqBody, _ := xml.Marshal(&RestoreRequest{Days: days, CASJobParameters: CASJobParameters{Tier: tier}})
+	query := url.Values{}
+	query.Set("restore", "")
+	header := http.Header{}
+	header.Set("Content-Type", "application/xml")
+	req := c.genReq(http.MethodPost, fileId, query, header, reqBody)
+
+	// 发送 HTTP 请求。
+	rsp, err := c.sendHttp(ctx, OperationPostObjectRestore, req)
+	if errors.Is(err, ErrRestoreAlreadyInProgress) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	c.closeRsp(rsp)
+
+	return nil
+}
+
+// WaitRestored 等待归档文件回热完成。
+func (c *restoreImpl) WaitRestored(ctx context.Context, fileId string, interval time.Duration) error {
+	fileId = suitFileId(fileId)
+	if len(fileId) <= 0 {
+		return errors.New("fileId is invalid")
+	}
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	for {
+		rsp, err := c.head(ctx, fileId)
+		if err != nil {
+			return err
+		}
+		info := c.parseFileInfo(rsp.Header)
+		if info.Readable() {
+			return nil
+		}
+		if !info.Restoring {
+			return fmt.Errorf("%w: file is archived and not being restored", ErrInvalidObjectState)
+		}
+		if err = sleepWithContext(ctx, interval); err != nil {
+			return err
+		}
+	}
+}
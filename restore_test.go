@@ -0,0 +1,177 @@
+/*
+ * Copyright (c) 2025 ivfzhou
+ * tencent-cos-object-api is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package cos_test
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	cos "gitee.com/ivfzhou/tencent-cos-object-api"
+)
+
+func TestRestore(t *testing.T) {
+	t.Run("正常运行", func(t *testing.T) {
+		for _, tier := range []cos.RestoreTier{"", cos.RestoreTierExpedited, cos.RestoreTierBulk} {
+			fileId := "/ivfzhou_test_file"
+			atomic.StoreInt32(&CloseCount, 0)
+			fn := func(req *http.Request) (*http.Response, error) {
+				if req.URL.Path != fileId {
+					t.Errorf("unexpected req path: want %v, got %v", fileId, req.URL.Path)
+				}
+				if req.Method != http.MethodPost {
+					t.Errorf("unexpected method: want %v, got %v", http.MethodPost, req.Method)
+				}
+				if !req.URL.Query().Has("restore") {
+					t.Errorf("unexpected query: want restore, got %v", req.URL.RawQuery)
+				}
+				auth := req.Header.Get("Authorization")
+				if !CheckAuthorization(auth, req.URL.Path, req.Method, req.Header, req.URL.Query()) {
+					t.Errorf("unexpected auth: got %v", auth)
+				}
+				bs, err := io.ReadAll(req.Body)
+				if err != nil {
+					t.Errorf("unexpected error: want nil, got %v", err)
+				}
+				var reqObj struct {
+					XMLName          xml.Name `xml:"RestoreRequest"`
+					Days             int
+					CASJobParameters struct {
+						Tier string
+					}
+				}
+				if err = xml.Unmarshal(bs, &reqObj); err != nil {
+					t.Errorf("unexpected error: want nil, got %v", err)
+				}
+				if reqObj.Days != 3 {
+					t.Errorf("unexpected days: want 3, got %v", reqObj.Days)
+				}
+				wantTier := string(tier)
+				if len(wantTier) <= 0 {
+					wantTier = string(cos.RestoreTierStandard)
+				}
+				if reqObj.CASJobParameters.Tier != wantTier {
+					t.Errorf("unexpected tier: want %v, got %v", wantTier, reqObj.CASJobParameters.Tier)
+				}
+				return &http.Response{
+					StatusCode: http.StatusAccepted,
+					Body:       NewReader(nil, nil, nil, nil),
+				}, nil
+			}
+			err := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn))).
+				Restore(context.Background(), fileId, 3, tier)
+			if err != nil {
+				t.Errorf("unexpected error: want nil, got %v", err)
+			}
+			if closeCount := atomic.LoadInt32(&CloseCount); closeCount != 0 {
+				t.Errorf("unexpected close count: want 0, got %v", closeCount)
+			}
+		}
+	})
+
+	t.Run("已在回热中", func(t *testing.T) {
+		fn := func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusConflict,
+				Body: NewReader([]byte("<Error><Code>RestoreAlreadyInProgress</Code>"+
+					"<Message>restore already in progress</Message></Error>"), nil, nil, nil),
+			}, nil
+		}
+		err := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn))).
+			Restore(context.Background(), "/ivfzhou_test_file", 1, cos.RestoreTierStandard)
+		if err != nil {
+			t.Errorf("unexpected error: want nil, got %v", err)
+		}
+	})
+
+	t.Run("参数错误", func(t *testing.T) {
+		err := cos.NewClient(host, appKey, appSecret).
+			Restore(context.Background(), "/ivfzhou_test_file", 0, cos.RestoreTierStandard)
+		if err == nil {
+			t.Errorf("unexpected error: want not nil, got %v", err)
+		}
+	})
+}
+
+func TestWaitRestored(t *testing.T) {
+	t.Run("等待回热完成", func(t *testing.T) {
+		var count int32
+		fn := func(req *http.Request) (*http.Response, error) {
+			if req.Method != http.MethodHead {
+				t.Errorf("unexpected method: want %v, got %v", http.MethodHead, req.Method)
+			}
+			header := http.Header{}
+			header.Set("x-cos-storage-class", string(cos.StorageClassDeepArchive))
+			if atomic.AddInt32(&count, 1) < 3 {
+				header.Set("x-cos-restore", `ongoing-request="true"`)
+			} else {
+				header.Set("x-cos-restore", `ongoing-request="false", expiry-date="Wed, 27 Jul 2022 13:02:03 GMT"`)
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     header,
+				Body:       NewReader(nil, nil, nil, nil),
+			}, nil
+		}
+		err := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn))).
+			WaitRestored(context.Background(), "/ivfzhou_test_file", time.Millisecond)
+		if err != nil {
+			t.Errorf("unexpected error: want nil, got %v", err)
+		}
+		if n := atomic.LoadInt32(&count); n != 3 {
+			t.Errorf("unexpected count: want 3, got %v", n)
+		}
+	})
+
+	t.Run("没有回热", func(t *testing.T) {
+		fn := func(req *http.Request) (*http.Response, error) {
+			header := http.Header{}
+			header.Set("x-cos-storage-class", string(cos.StorageClassArchive))
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     header,
+				Body:       NewReader(nil, nil, nil, nil),
+			}, nil
+		}
+		err := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn))).
+			WaitRestored(context.Background(), "/ivfzhou_test_file", time.Millisecond)
+		if !errors.Is(err, cos.ErrInvalidObjectState) {
+			t.Errorf("unexpected error: want %v, got %v", cos.ErrInvalidObjectState, err)
+		}
+	})
+
+	t.Run("上下文终止", func(t *testing.T) {
+		fn := func(req *http.Request) (*http.Response, error) {
+			header := http.Header{}
+			header.Set("x-cos-storage-class", string(cos.StorageClassArchive))
+			header.Set("x-cos-restore", `ongoing-request="true"`)
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     header,
+				Body:       NewReader(nil, nil, nil, nil),
+			}, nil
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+		defer cancel()
+		err := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn))).
+			WaitRestored(ctx, "/ivfzhou_test_file", time.Millisecond*10)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("unexpected error: want %v, got %v", context.DeadlineExceeded, err)
+		}
+	})
+}
@@ -0,0 +1,120 @@
+/*
+ * Copyright (c) 2025 ivfzhou
+ * tencent-cos-object-api is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package cos
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// DefaultRetryPolicy 默认的重试策略，可配合 WithRetryPolicy 使用。
+var DefaultRetryPolicy = &RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   200 * time.Millisecond,
+	MaxDelay:    5 * time.Second,
+}
+
+// RetryPolicy 请求失败后的重试策略。
+type RetryPolicy struct {
+	// MaxAttempts 最多尝试次数，包含第一次请求。小于等于 1 时不重试。
+	MaxAttempts int
+	// BaseDelay 第一次重试前的等待时间，之后每次翻倍，并加入随机抖动。
+	BaseDelay time.Duration
+	// MaxDelay 重试等待时间的上限。小于等于 0 时不设上限。
+	MaxDelay time.Duration
+	// Retryable 判断错误是否可以重试。为 nil 时，重试 5xx、429 响应码、限流错误码、连接重置和超时错误。
+	Retryable func(err error) bool
+}
+
+// 可重试的错误码。
+var retryableCodes = map[string]bool{
+	"SlowDown":           true,
+	"RequestTimeout":     true,
+	"InternalError":      true,
+	"ServiceUnavailable": true,
+}
+
+// 判断第 attempt 次请求失败后是否要重试。
+func (p *RetryPolicy) shouldRetry(ctx context.Context, attempt int, err error) bool {
+	if p == nil || attempt >= p.MaxAttempts || ctx.Err() != nil {
+		return false
+	}
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return isRetryable(err)
+}
+
+// 计算第 attempt 次请求失败后的等待时间。
+func (p *RetryPolicy) delay(attempt int, err error) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < attempt && d > 0; i++ {
+		d *= 2
+		if p.MaxDelay > 0 && d >= p.MaxDelay {
+			break
+		}
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if d > 0 {
+		d = d/2 + rand.N(d/2+1) // 随机抖动，避免并发的分片同时重试。
+	}
+
+	// 服务端要求的等待时间优先。
+	var cosErr *Error
+	if errors.As(err, &cosErr) && cosErr.retryAfter > d {
+		d = cosErr.retryAfter
+	}
+
+	return d
+}
+
+// 默认的可重试错误判断。
+func isRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var cosErr *Error
+	if errors.As(err, &cosErr) {
+		return cosErr.StatusCode >= http.StatusInternalServerError ||
+			cosErr.StatusCode == http.StatusTooManyRequests || retryableCodes[cosErr.Code]
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+}
+
+// 等待一段时间，上下文终止则提前返回。
+func sleepWithContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,209 @@
+/*
+ * Copyright (c) 2025 ivfzhou
+ * tencent-cos-object-api is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package cos_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	cos "gitee.com/ivfzhou/tencent-cos-object-api"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	policy := &cos.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}
+
+	t.Run("重试后成功", func(t *testing.T) {
+		for range 10 {
+			atomic.StoreInt32(&CloseCount, 0)
+			data := MakeBytesWithSize(1024)
+			var count int32
+			fn := func(req *http.Request) (*http.Response, error) {
+				bs, err := io.ReadAll(req.Body)
+				if err != nil {
+					t.Errorf("unexpected error: want nil, got %v", err)
+				}
+				if !bytes.Equal(bs, data) {
+					t.Errorf("unexpected result: want %v, got %v", len(data), len(bs))
+				}
+				if atomic.AddInt32(&count, 1) < 3 {
+					return &http.Response{
+						StatusCode: http.StatusServiceUnavailable,
+						Body:       NewReader([]byte("<Error><Code>SlowDown</Code></Error>"), nil, nil, nil),
+					}, nil
+				}
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       NewReader(nil, nil, nil, nil),
+				}, nil
+			}
+			client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)),
+				cos.WithRetryPolicy(policy))
+			err := client.Upload(context.Background(), "ivfzhou_test_file", data)
+			if err != nil {
+				t.Errorf("unexpected error: want nil, got %v", err)
+			}
+			if n := atomic.LoadInt32(&count); n != 3 {
+				t.Errorf("unexpected attempts: want 3, got %v", n)
+			}
+			if closeCount := atomic.LoadInt32(&CloseCount); closeCount != 0 {
+				t.Errorf("unexpected close count: want 0, got %v", closeCount)
+			}
+		}
+	})
+
+	t.Run("重试次数用尽", func(t *testing.T) {
+		atomic.StoreInt32(&CloseCount, 0)
+		var count int32
+		fn := func(req *http.Request) (*http.Response, error) {
+			atomic.AddInt32(&count, 1)
+			return &http.Response{
+				StatusCode: http.StatusInternalServerError,
+				Body:       NewReader([]byte("<Error><Code>InternalError</Code></Error>"), nil, nil, nil),
+			}, nil
+		}
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)),
+			cos.WithRetryPolicy(policy))
+		err := client.Delete(context.Background(), "ivfzhou_test_file")
+		var cosErr *cos.Error
+		if !errors.As(err, &cosErr) || cosErr.Code != "InternalError" {
+			t.Errorf("unexpected error: want InternalError, got %v", err)
+		}
+		if n := atomic.LoadInt32(&count); n != int32(policy.MaxAttempts) {
+			t.Errorf("unexpected attempts: want %v, got %v", policy.MaxAttempts, n)
+		}
+		if closeCount := atomic.LoadInt32(&CloseCount); closeCount != 0 {
+			t.Errorf("unexpected close count: want 0, got %v", closeCount)
+		}
+	})
+
+	t.Run("不可重试的错误", func(t *testing.T) {
+		var count int32
+		fn := func(req *http.Request) (*http.Response, error) {
+			atomic.AddInt32(&count, 1)
+			return &http.Response{
+				StatusCode: http.StatusForbidden,
+				Body:       NewReader([]byte("<Error><Code>AccessDenied</Code></Error>"), nil, nil, nil),
+			}, nil
+		}
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)),
+			cos.WithRetryPolicy(policy))
+		err := client.Delete(context.Background(), "ivfzhou_test_file")
+		if !errors.Is(err, cos.ErrAccessDenied) {
+			t.Errorf("unexpected error: want %v, got %v", cos.ErrAccessDenied, err)
+		}
+		if n := atomic.LoadInt32(&count); n != 1 {
+			t.Errorf("unexpected attempts: want 1, got %v", n)
+		}
+	})
+
+	t.Run("遵循 Retry-After", func(t *testing.T) {
+		var count int32
+		fn := func(req *http.Request) (*http.Response, error) {
+			if atomic.AddInt32(&count, 1) == 1 {
+				header := http.Header{}
+				header.Set("Retry-After", "1")
+				return &http.Response{
+					StatusCode: http.StatusServiceUnavailable,
+					Header:     header,
+					Body:       NewReader(nil, nil, nil, nil),
+				}, nil
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       NewReader(nil, nil, nil, nil),
+			}, nil
+		}
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)),
+			cos.WithRetryPolicy(policy))
+		now := time.Now()
+		if err := client.Delete(context.Background(), "ivfzhou_test_file"); err != nil {
+			t.Errorf("unexpected error: want nil, got %v", err)
+		}
+		if cost := time.Since(now); cost < time.Second {
+			t.Errorf("unexpected cost: want >= 1s, got %v", cost)
+		}
+	})
+
+	t.Run("上下文终止", func(t *testing.T) {
+		expectedErr := errors.New("expected error")
+		ctx, cancel := NewCtxCancelWithError()
+		fn := func(req *http.Request) (*http.Response, error) {
+			cancel(expectedErr)
+			return &http.Response{
+				StatusCode: http.StatusServiceUnavailable,
+				Body:       NewReader(nil, nil, nil, nil),
+			}, nil
+		}
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)),
+			cos.WithRetryPolicy(&cos.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Hour}))
+		err := client.Delete(ctx, "ivfzhou_test_file")
+		if err == nil {
+			t.Errorf("unexpected error: want not nil, got %v", err)
+		}
+	})
+
+	t.Run("分片下载断点续传", func(t *testing.T) {
+		for range 5 {
+			partSize := int64(1024 * 1024)
+			data := MakeBytesWithSize(int(partSize)*3 + 1024)
+			broken := sync.Map{}
+			fn := func(req *http.Request) (*http.Response, error) {
+				if req.Method == http.MethodHead {
+					return &http.Response{
+						StatusCode:    http.StatusOK,
+						ContentLength: int64(len(data)),
+						Body:          NewReader(nil, nil, nil, nil),
+					}, nil
+				}
+				var begin, end int64
+				if _, err := fmt.Sscanf(req.Header.Get("Range"), "bytes=%d-%d", &begin, &end); err != nil {
+					t.Errorf("unexpected error: want nil, got %v", err)
+				}
+				part := data[begin : end+1]
+				if _, loaded := broken.LoadOrStore(strconv.FormatInt(begin/partSize, 10), true); !loaded {
+					return &http.Response{
+						StatusCode: http.StatusPartialContent,
+						Body: io.NopCloser(io.MultiReader(bytes.NewReader(part[:len(part)/2]),
+							iotest.ErrReader(io.ErrUnexpectedEOF))),
+					}, nil
+				}
+				return &http.Response{
+					StatusCode: http.StatusPartialContent,
+					Body:       io.NopCloser(bytes.NewReader(part)),
+				}, nil
+			}
+			client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)),
+				cos.WithRetryPolicy(policy), cos.WithPartSize(partSize), cos.WithMultiThreshold(1))
+			result := make([]byte, len(data))
+			wa := NewWriterAt(func(p []byte, off int64) (int, error) {
+				return copy(result[off:], p), nil
+			})
+			if err := client.DownloadToWriterAt(context.Background(), "ivfzhou_test_file", wa); err != nil {
+				t.Errorf("unexpected error: want nil, got %v", err)
+			}
+			if !bytes.Equal(result, data) {
+				t.Errorf("unexpected result: want %v, got %v", len(data), len(result))
+			}
+		}
+	})
+}
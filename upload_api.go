@@ -15,6 +15,8 @@ package cos
 import (
 	"context"
 	"io"
+	"net/http"
+	"time"
 )
 
 // FilePartInfo 文件分片信息。
@@ -27,18 +29,64 @@ type FilePartInfo struct {
 	Size int64
 }
 
+// UploadResult 上传结果，由 WithResult 结尾的上传方法返回。
+type UploadResult struct {
+	// EntityTag 文件的 ETag。分片上传的 ETag 不是文件内容的 MD5。
+	EntityTag string
+	// Crc64 服务端计算的文件 CRC64 值。
+	Crc64 string
+	// VersionId 上传生成的文件版本 ID，存储桶没有开启版本控制时为空。
+	VersionId string
+	// Size 文件大小。
+	Size int64
+	// PartCount 分片数量，非分片上传时为 1。
+	PartCount int
+	// RequestId 上传请求的 ID，分片上传时是合并分片请求的 ID。
+	RequestId string
+}
+
+// Uploader 上传文件。opts 可覆盖客户端的参数，只对本次上传生效。
 type Uploader interface {
 	// Upload 上传文件。
-	Upload(ctx context.Context, fileId string, content []byte) error
+	Upload(ctx context.Context, fileId string, content []byte, opts ...option) error
+
+	// UploadWithResult 上传文件，返回上传结果。
+	UploadWithResult(ctx context.Context, fileId string, content []byte, opts ...option) (*UploadResult, error)
 
 	// UploadFromReader 上传文件。
-	UploadFromReader(ctx context.Context, fileId string, r io.Reader) error
+	UploadFromReader(ctx context.Context, fileId string, r io.Reader, opts ...option) error
+
+	// UploadFromReaderWithResult 上传文件，返回上传结果。
+	UploadFromReaderWithResult(ctx context.Context, fileId string, r io.Reader, opts ...option) (*UploadResult, error)
 
 	// UploadFromReaderWithSize 上传文件。
-	UploadFromReaderWithSize(ctx context.Context, fileId string, contentLength int64, r io.Reader) error
+	UploadFromReaderWithSize(ctx context.Context, fileId string, contentLength int64, r io.Reader,
+		opts ...option) error
+
+	// UploadFromReaderWithSizeWithResult 上传文件，返回上传结果。
+	UploadFromReaderWithSizeWithResult(ctx context.Context, fileId string, contentLength int64, r io.Reader,
+		opts ...option) (*UploadResult, error)
 
 	// UploadFromDisk 上传文件。
-	UploadFromDisk(ctx context.Context, fileId, filePath string) error
+	UploadFromDisk(ctx context.Context, fileId, filePath string, opts ...option) error
+
+	// UploadFromDiskWithResult 上传文件，返回上传结果。
+	UploadFromDiskWithResult(ctx context.Context, fileId, filePath string, opts ...option) (*UploadResult, error)
+
+	// ResumableUploadFromDisk 断点续传上传文件。
+	//
+	// 上传进度保存在 checkpointPath 检查点文件中，为空时使用 filePath + ".cos-upload.cp"。上传失败时保留已上传的分片，
+	// 再次调用会核对服务端已上传的分片，只上传缺失的分片。本地文件有变化时丢弃之前上传的分片并重新上传。
+	// 上传成功后删除检查点文件。
+	ResumableUploadFromDisk(ctx context.Context, fileId, filePath, checkpointPath string, opts ...option) error
+
+	// ResumableUploadFromDiskWithResult 断点续传上传文件，返回上传结果。
+	ResumableUploadFromDiskWithResult(ctx context.Context, fileId, filePath, checkpointPath string,
+		opts ...option) (*UploadResult, error)
+
+	// GetUploadUrl 获取文件上传链接，使用 PUT 方法上传。opts 设置的文件头、元数据、存储类型、加密方式会参与签名，
+	// 上传时必须携带 header 中的 HTTP 头。
+	GetUploadUrl(fileId string, expiration time.Duration, opts ...option) (uploadUrl string, header http.Header)
 
 	MultiUploader
 }
@@ -16,45 +16,69 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"hash/crc64"
 	"io"
 	"net/http"
 	"os"
+	"sync"
+	"time"
 
 	gu "gitee.com/ivfzhou/goroutine-util"
 )
 
 type uploadImpl struct {
 	*baseImpl
-	MultiUploader
+	*multiUploadImpl
 }
 
 // Upload 上传文件。
-func (c *uploadImpl) Upload(ctx context.Context, fileId string, reqBody []byte) error {
+func (c *uploadImpl) Upload(ctx context.Context, fileId string, reqBody []byte, opts ...option) error {
+	_, err := c.UploadWithResult(ctx, fileId, reqBody, opts...)
+	return err
+}
+
+// UploadWithResult 上传文件，返回上传结果。
+func (c *uploadImpl) UploadWithResult(ctx context.Context, fileId string, reqBody []byte, opts ...option) (
+	*UploadResult, error) {
+
 	fileId = suitFileId(fileId)
 	if len(fileId) <= 0 {
-		return errors.New("fileId is invalid")
+		return nil, errors.New("fileId is invalid")
 	}
+	c = c.with(opts)
 
 	// 是否启用分片模式上传。
 	size := int64(len(reqBody))
-	if useMultipart(size) {
-		return c.multiUploadFromReaderWithSize(ctx, fileId, size, bytes.NewReader(reqBody))
+	p := c.newProgress(fileId, size)
+	if c.useMultipart(size) {
+		return c.multiUploadFromReaderWithSize(ctx, fileId, size, bytes.NewReader(reqBody), p)
 	}
 
-	return c.upload(ctx, fileId, reqBody)
+	return c.uploadFromReaderWithSize(ctx, fileId, size, bytes.NewReader(reqBody), p)
 }
 
 // UploadFromReader 上传文件。
-func (c *uploadImpl) UploadFromReader(ctx context.Context, fileId string, r io.Reader) error {
+func (c *uploadImpl) UploadFromReader(ctx context.Context, fileId string, r io.Reader, opts ...option) error {
+	_, err := c.UploadFromReaderWithResult(ctx, fileId, r, opts...)
+	return err
+}
+
+// UploadFromReaderWithResult 上传文件，返回上传结果。
+func (c *uploadImpl) UploadFromReaderWithResult(ctx context.Context, fileId string, r io.Reader, opts ...option) (
+	*UploadResult, error) {
+
 	fileId = suitFileId(fileId)
 	if len(fileId) <= 0 {
-		return errors.New("fileId is invalid")
+		return nil, errors.New("fileId is invalid")
 	}
+	c = c.with(opts)
+	p := newProgress(c.progress, fileId, -1, -1)
+	crcs := c.newCrc64Parts()
 
 	// 初始化上传。
 	uploadId, err := c.InitMultiUpload(ctx, fileId)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// 并发上传。
@@ -62,140 +86,356 @@ func (c *uploadImpl) UploadFromReader(ctx context.Context, fileId string, r io.R
 		buf []byte
 		num int64
 	}
-	run, wait := gu.NewRunner(ctx, NumRoutines, func(ctx context.Context, t *data) error {
-		defer rollbackBytes(t.buf)
-		return c.UploadPart(ctx, fileId, uploadId, t.num, t.buf)
+	run, wait := gu.NewRunner(ctx, c.numRoutines, func(ctx context.Context, t *data) error {
+		defer c.rollbackBytes(t.buf)
+		return c.uploadPartWithProgress(ctx, fileId, uploadId, t.num, t.buf, p, crcs)
 	})
 
 	for i, next, n := 1, true, 0; next; i++ {
-		buf := makeBytes()
+		buf := c.makeBytes()
 		n, err = io.ReadFull(r, buf)
 		if err != nil {
 			if errors.Is(err, io.EOF) {
+				err = nil
 				break
 			}
 			if errors.Is(err, io.ErrUnexpectedEOF) {
 				next = false
 			} else {
-				return err
+				break
 			}
 		}
 		if err = run(&data{buf[:n], int64(i)}, false); err != nil {
-			return err
+			break
 		}
 	}
-
-	if err = wait(true); err != nil {
+	if err == nil {
+		err = wait(true)
+	}
+	if err != nil {
+		_ = wait(false) // 等待所有协程退出，返回后不再回调进度。
 		noCancelCtx := context.WithoutCancel(ctx)
-		go func() { // 出错就丢弃已上传的分片。
-			_ = wait(false)
-			printError(c.AbortMultiUpload(noCancelCtx, fileId, uploadId))
-		}()
-		return err
+		go c.tryAbortMultiUpload(noCancelCtx, fileId, uploadId) // 出错就丢弃已上传的分片。
+		return nil, err
 	}
 
 	// 合并分片，结束上传。
-	if err = c.CompleteMultiUpload(ctx, fileId, uploadId); err != nil {
+	result, err := c.completeMultiUpload(ctx, fileId, uploadId, c.mergeConditionHeader(nil))
+	if err != nil {
 		noCancelCtx := context.WithoutCancel(ctx)
 		go func() { // 出错就丢弃已上传的分片。
-			printError(c.AbortMultiUpload(noCancelCtx, fileId, uploadId))
+			c.tryAbortMultiUpload(noCancelCtx, fileId, uploadId)
 		}()
+		return nil, err
 	}
 
-	return err
+	if err = crcs.check(fileId, result.Crc64); err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
 // UploadFromReaderWithSize 上传文件。
 func (c *uploadImpl) UploadFromReaderWithSize(ctx context.Context, fileId string, contentLength int64,
-	r io.Reader) error {
+	r io.Reader, opts ...option) error {
+
+	_, err := c.UploadFromReaderWithSizeWithResult(ctx, fileId, contentLength, r, opts...)
+	return err
+}
+
+// UploadFromReaderWithSizeWithResult 上传文件，返回上传结果。
+func (c *uploadImpl) UploadFromReaderWithSizeWithResult(ctx context.Context, fileId string, contentLength int64,
+	r io.Reader, opts ...option) (*UploadResult, error) {
 
 	fileId = suitFileId(fileId)
 	if len(fileId) <= 0 {
-		return errors.New("fileId is invalid")
+		return nil, errors.New("fileId is invalid")
 	}
+	c = c.with(opts)
 
 	// 是否启用分片模式上传。
-	if useMultipart(contentLength) {
-		return c.multiUploadFromReaderWithSize(ctx, fileId, contentLength, r)
+	p := c.newProgress(fileId, contentLength)
+	if c.useMultipart(contentLength) {
+		return c.multiUploadFromReaderWithSize(ctx, fileId, contentLength, r, p)
 	}
 
-	return c.uploadFromReaderWithSize(ctx, fileId, contentLength, io.NopCloser(r))
+	return c.uploadFromReaderWithSize(ctx, fileId, contentLength, r, p)
 }
 
 // UploadFromDisk 上传文件。
-func (c *uploadImpl) UploadFromDisk(ctx context.Context, fileId, filePath string) error {
+func (c *uploadImpl) UploadFromDisk(ctx context.Context, fileId, filePath string, opts ...option) error {
+	_, err := c.UploadFromDiskWithResult(ctx, fileId, filePath, opts...)
+	return err
+}
+
+// UploadFromDiskWithResult 上传文件，返回上传结果。
+func (c *uploadImpl) UploadFromDiskWithResult(ctx context.Context, fileId, filePath string, opts ...option) (
+	*UploadResult, error) {
+
 	fileId = suitFileId(fileId)
 	if len(fileId) <= 0 {
-		return errors.New("fileId is invalid")
+		return nil, errors.New("fileId is invalid")
 	}
+	c = c.with(opts)
 
 	// 获取文件信息。
 	fileInfo, err := os.Stat(filePath)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// 打开文件流。
 	fileObj, err := os.Open(filePath)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer closeIO(fileObj)
+	defer c.closeIO(fileObj)
 	size := fileInfo.Size()
 
 	// 是否启用分片模式上传。
-	if useMultipart(size) {
-		return c.multiUploadFromReaderWithSize(ctx, fileId, size, fileObj)
+	p := c.newProgress(fileId, size)
+	if c.useMultipart(size) {
+		return c.multiUploadFromReaderWithSize(ctx, fileId, size, fileObj, p)
 	}
 
-	return c.uploadFromReaderWithSize(ctx, fileId, size, fileObj)
+	return c.uploadFromReaderWithSize(ctx, fileId, size, fileObj, p)
 }
 
-// 上传文件。
-func (c *uploadImpl) upload(ctx context.Context, fileId string, content []byte) error {
-	req := c.genReq(http.MethodPut, fileId, nil, nil, content)
-	rsp, err := c.sendHttp(ctx, req)
+// ResumableUploadFromDisk 断点续传上传文件。
+func (c *uploadImpl) ResumableUploadFromDisk(ctx context.Context, fileId, filePath, checkpointPath string,
+	opts ...option) error {
+
+	_, err := c.ResumableUploadFromDiskWithResult(ctx, fileId, filePath, checkpointPath, opts...)
+	return err
+}
+
+// ResumableUploadFromDiskWithResult 断点续传上传文件，返回上传结果。
+func (c *uploadImpl) ResumableUploadFromDiskWithResult(ctx context.Context, fileId, filePath, checkpointPath string,
+	opts ...option) (*UploadResult, error) {
+
+	fileId = suitFileId(fileId)
+	if len(fileId) <= 0 {
+		return nil, errors.New("fileId is invalid")
+	}
+	c = c.with(opts)
+	if len(checkpointPath) <= 0 {
+		checkpointPath = filePath + uploadCheckpointSuffix
+	}
+
+	// 获取文件信息。
+	fileInfo, err := os.Stat(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	// 打开文件流。
+	fileObj, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer c.closeIO(fileObj)
+	size := fileInfo.Size()
+
+	// 读取检查点，与本地文件不匹配时丢弃之前上传的分片。
+	cp := &uploadCheckpoint{}
+	if err = loadCheckpoint(checkpointPath, cp); err != nil {
+		cp = nil
+	} else if !c.useMultipart(size) || !cp.match(fileId, fileInfo, c.partSize) {
+		if len(cp.FileId) > 0 && len(cp.UploadId) > 0 {
+			c.tryAbortMultiUpload(ctx, cp.FileId, cp.UploadId)
+		}
+		cp = nil
+	}
+
+	// 文件较小，直接上传。
+	if !c.useMultipart(size) {
+		p := c.newProgress(fileId, size)
+		result, err := c.uploadFromReaderWithSize(ctx, fileId, size, fileObj, p)
+		if err == nil {
+			c.removeCheckpoint(checkpointPath)
+		}
+		return result, err
+	}
+
+	// 与服务端已上传的分片核对。
+	if cp != nil {
+		var parts []*FilePartInfo
+		parts, err = c.ListFileParts(ctx, fileId, cp.UploadId)
+		if errors.Is(err, ErrNoSuchUpload) || errors.Is(err, ErrNotExists) {
+			cp = nil
+		} else if err != nil {
+			return nil, err
+		} else {
+			cp.Parts = make(map[int64]string, len(parts))
+			for _, v := range parts {
+				offset := int64(v.PartNumber-1) * c.partSize
+				if offset < size && v.Size == min(c.partSize, size-offset) {
+					cp.Parts[int64(v.PartNumber)] = v.EntityTag
+				}
+			}
+		}
+	}
+
+	// 没有可用的检查点，初始化分片上传。
+	if cp == nil {
+		uploadId, err := c.InitMultiUpload(ctx, fileId)
+		if err != nil {
+			return nil, err
+		}
+		cp = &uploadCheckpoint{
+			FileId:   fileId,
+			UploadId: uploadId,
+			PartSize: c.partSize,
+			FileSize: size,
+			ModTime:  fileInfo.ModTime().UnixNano(),
+			Parts:    make(map[int64]string),
+		}
+	}
+	if err = saveCheckpoint(checkpointPath, cp); err != nil {
+		return nil, err
+	}
+
+	// 并发上传缺失的分片，每上传完一个分片就更新检查点。
+	type data struct {
+		num, offset, size int64
+	}
+	p := c.newProgress(fileId, size)
+	uploaded := make(map[int64]bool, len(cp.Parts)) // 检查点会被并发修改，分发前复制一份已上传的分片。
+	for num := range cp.Parts {
+		uploaded[num] = true
+		p.skipPart(min(c.partSize, size-(num-1)*c.partSize))
+	}
+	crcs := c.newCrc64Parts()
+	lock := sync.Mutex{}
+	run, wait := gu.NewRunner(ctx, c.numRoutines, func(ctx context.Context, t *data) error {
+		var body io.Reader = io.NewSectionReader(fileObj, t.offset, t.size)
+		cr := c.crc64Reader(body)
+		if cr != nil {
+			body = cr
+		}
+		r := p.reader(body)
+		etag, err := c.uploadPart(ctx, fileId, cp.UploadId, t.num, t.size, r)
+		p.readerDone(r, err)
+		if err != nil {
+			return err
+		}
+		if cr != nil {
+			crcs.add(t.num, cr.crc, t.size)
+		}
+		lock.Lock()
+		defer lock.Unlock()
+		cp.Parts[t.num] = etag
+		return saveCheckpoint(checkpointPath, cp)
+	})
+	for num, offset := int64(1), int64(0); offset < size; num, offset = num+1, offset+c.partSize {
+		if uploaded[num] {
+			continue
+		}
+		if err = run(&data{num, offset, min(c.partSize, size-offset)}, false); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = wait(true)
+	}
 	if err != nil {
-		return err
+		_ = wait(false) // 等待所有协程退出，返回后不再写检查点、回调进度。
+		return nil, err
+	}
+
+	// 之前上传的分片从本地文件计算校验值。
+	if crcs != nil {
+		for num := range uploaded {
+			offset := (num - 1) * c.partSize
+			crc, err := readCrc64(io.NewSectionReader(fileObj, offset, min(c.partSize, size-offset)))
+			if err != nil {
+				return nil, err
+			}
+			crcs.add(num, crc, min(c.partSize, size-offset))
+		}
 	}
-	closeRsp(rsp)
-	return nil
+
+	// 合并分片。
+	result, err := c.completeMultiUpload(ctx, fileId, cp.UploadId, c.mergeConditionHeader(nil))
+	if err != nil {
+		// 条件不满足时，再次调用也不会成功，丢弃已上传的分片。
+		if errors.Is(err, ErrPreconditionFailed) {
+			c.tryAbortMultiUpload(context.WithoutCancel(ctx), fileId, cp.UploadId)
+			c.removeCheckpoint(checkpointPath)
+		}
+		return nil, err
+	}
+	c.removeCheckpoint(checkpointPath)
+
+	if err = crcs.check(fileId, result.Crc64); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetUploadUrl 获取文件上传链接。
+func (c *uploadImpl) GetUploadUrl(fileId string, expiration time.Duration, opts ...option) (string, http.Header) {
+	c = c.with(opts)
+	header := c.mergeConditionHeader(c.mergeUploadHeader(nil))
+	return c.PresignUrl(http.MethodPut, fileId, nil, header, expiration), header
+}
+
+// 使用调用参数覆盖客户端参数。
+func (c *uploadImpl) with(opts []option) *uploadImpl {
+	b := c.baseImpl.with(opts)
+	if b == c.baseImpl {
+		return c
+	}
+	return &uploadImpl{b, &multiUploadImpl{b}}
 }
 
 // 上传文件。
 func (c *uploadImpl) uploadFromReaderWithSize(ctx context.Context, fileId string, contentLength int64,
-	rc io.ReadCloser) error {
+	r io.Reader, p *progress) (*UploadResult, error) {
 
-	req := c.genReqForReader(http.MethodPut, fileId, nil, nil, contentLength, rc)
-	rsp, err := c.sendHttp(ctx, req)
+	cr := c.crc64Reader(r)
+	if cr != nil {
+		r = cr
+	}
+	r = p.reader(r)
+	header := c.mergeConditionHeader(c.mergeUploadHeader(nil))
+	req := c.genReqForReader(http.MethodPut, fileId, nil, header, contentLength, r)
+	rsp, err := c.sendHttp(ctx, OperationPutObject, req)
+	p.readerDone(r, err)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	c.closeRsp(rsp)
+	if cr != nil {
+		if err = checkCrc64(fileId, cr.crc, rsp.Header.Get("x-cos-hash-crc64ecma")); err != nil {
+			return nil, err
+		}
 	}
-	closeRsp(rsp)
-	return nil
+	return newUploadResult(rsp.Header, contentLength, 1), nil
 }
 
 // 从读取流中读取上传文件。
 func (c *uploadImpl) multiUploadFromReaderWithSize(ctx context.Context, fileId string, contentLength int64,
-	r io.Reader) error {
+	r io.Reader, p *progress) (*UploadResult, error) {
 
 	// 初始化分片上传。
 	uploadId, err := c.InitMultiUpload(ctx, fileId)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	type data struct {
 		buf []byte
 		num int64
 	}
-	run, wait := gu.NewRunner(ctx, NumRoutines, func(ctx context.Context, t *data) error {
-		defer rollbackBytes(t.buf)
-		return c.UploadPart(ctx, fileId, uploadId, t.num, t.buf)
+	crcs := c.newCrc64Parts()
+	run, wait := gu.NewRunner(ctx, c.numRoutines, func(ctx context.Context, t *data) error {
+		defer c.rollbackBytes(t.buf)
+		return c.uploadPartWithProgress(ctx, fileId, uploadId, t.num, t.buf, p, crcs)
 	})
 
 	// 并发上传分片。
-	partSize := getPartSize()
+	partSize := c.partSize
 	for i, totalRead, n := 1, int64(0), int64(0); totalRead < contentLength; i, totalRead = i+1, totalRead+partSize {
 		n = partSize
 		var buf []byte
@@ -203,33 +443,62 @@ func (c *uploadImpl) multiUploadFromReaderWithSize(ctx context.Context, fileId s
 			n = contentLength - totalRead
 			buf = make([]byte, n)
 		} else {
-			buf = makeBytes()
+			buf = c.makeBytes()
 		}
-		_, err = io.ReadFull(r, buf)
-		if err != nil {
-			return err
+		if _, err = io.ReadFull(r, buf); err != nil {
+			break
 		}
 		if err = run(&data{buf, int64(i)}, false); err != nil {
-			return err
+			break
 		}
 	}
-
-	if err = wait(true); err != nil {
+	if err == nil {
+		err = wait(true)
+	}
+	if err != nil {
+		_ = wait(false) // 等待所有协程退出，返回后不再回调进度。
 		noCancelCtx := context.WithoutCancel(ctx)
-		go func() { // 出错就丢弃已上传的分片。
-			_ = wait(false) // 等待所有协程退出。
-			printError(c.AbortMultiUpload(noCancelCtx, fileId, uploadId))
-		}()
-		return err
+		go c.tryAbortMultiUpload(noCancelCtx, fileId, uploadId) // 出错就丢弃已上传的分片。
+		return nil, err
 	}
 
 	// 合并分片。
-	if err = c.CompleteMultiUpload(ctx, fileId, uploadId); err != nil {
+	result, err := c.completeMultiUpload(ctx, fileId, uploadId, c.mergeConditionHeader(nil))
+	if err != nil {
 		noCancelCtx := context.WithoutCancel(ctx)
 		go func() { // 出错就丢弃已上传的分片。
-			printError(c.AbortMultiUpload(noCancelCtx, fileId, uploadId))
+			c.tryAbortMultiUpload(noCancelCtx, fileId, uploadId)
 		}()
+		return nil, err
+	}
+
+	if err = crcs.check(fileId, result.Crc64); err != nil {
+		return nil, err
 	}
+	return result, nil
+}
 
+// 从响应头中生成上传结果。
+func newUploadResult(header http.Header, size int64, partCount int) *UploadResult {
+	return &UploadResult{
+		EntityTag: header.Get("Etag"),
+		Crc64:     header.Get("x-cos-hash-crc64ecma"),
+		VersionId: header.Get("x-cos-version-id"),
+		Size:      size,
+		PartCount: partCount,
+		RequestId: header.Get("x-cos-request-id"),
+	}
+}
+
+// 上传分片，统计传输进度，并记录分片的校验值。
+func (c *uploadImpl) uploadPartWithProgress(ctx context.Context, fileId, uploadId string, partNumber int64,
+	buf []byte, p *progress, crcs *crc64Parts) error {
+
+	r := p.reader(bytes.NewReader(buf))
+	_, err := c.uploadPart(ctx, fileId, uploadId, partNumber, int64(len(buf)), r)
+	p.readerDone(r, err)
+	if err == nil && crcs != nil {
+		crcs.add(partNumber, crc64.Checksum(buf, crc64Table), int64(len(buf)))
+	}
 	return err
 }
@@ -17,10 +17,14 @@ import (
 	"context"
 	"encoding/xml"
 	"errors"
+	"fmt"
+	"hash/crc64"
 	"io"
 	"math/rand"
 	"net/http"
 	"os"
+	"path/filepath"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -498,6 +502,257 @@ func TestUpload(t *testing.T) {
 		}
 	})
 
+	t.Run("自定义分片参数", func(t *testing.T) {
+		for range 10 {
+			partSize := int64(1024*1024 + rand.Intn(1024))
+			numRoutines := rand.Intn(3) + 1
+			data := MakeBytesWithSize(int(partSize)*(rand.Intn(3)+3) + rand.Intn(1024) + 1)
+			uploadId := "expected upload id"
+			fileId := "/ivfzhou_test_file"
+			var running, maxRunning int32
+			var partCount int32
+			atomic.StoreInt32(&CloseCount, 0)
+			fn := func(req *http.Request) (*http.Response, error) {
+				switch req.Method {
+				case http.MethodPut:
+					n := atomic.AddInt32(&running, 1)
+					defer atomic.AddInt32(&running, -1)
+					for {
+						m := atomic.LoadInt32(&maxRunning)
+						if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+							break
+						}
+					}
+					time.Sleep(time.Millisecond)
+					num, err := strconv.Atoi(req.URL.Query().Get("partNumber"))
+					if err != nil {
+						t.Errorf("unexpected error: want nil, got %v", err)
+					}
+					bs, err := io.ReadAll(req.Body)
+					if err != nil {
+						t.Errorf("unexpected error: want nil, got %v", err)
+					}
+					want := min(partSize, int64(len(data))-int64(num-1)*partSize)
+					if int64(len(bs)) != want {
+						t.Errorf("unexpected part size: want %v, got %v", want, len(bs))
+					}
+					atomic.AddInt32(&partCount, 1)
+				case http.MethodPost:
+					if req.URL.Query().Has("uploads") {
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body: NewReader([]byte("<InitiateMultipartUploadResult><UploadId>"+
+								uploadId+"</UploadId></InitiateMultipartUploadResult>"), nil, nil, nil),
+						}, nil
+					}
+				case http.MethodGet:
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       NewReader([]byte("<ListPartsResult></ListPartsResult>"), nil, nil, nil),
+					}, nil
+				}
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       NewReader(nil, nil, nil, nil),
+				}, nil
+			}
+			client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)),
+				cos.WithPartSize(partSize), cos.WithMultiThreshold(2), cos.WithNumRoutines(numRoutines))
+			err := client.Upload(context.Background(), fileId, data)
+			if err != nil {
+				t.Errorf("unexpected error: want nil, got %v", err)
+			}
+			wantCount := int32((int64(len(data)) + partSize - 1) / partSize)
+			if count := atomic.LoadInt32(&partCount); count != wantCount {
+				t.Errorf("unexpected part count: want %v, got %v", wantCount, count)
+			}
+			if n := atomic.LoadInt32(&maxRunning); n > int32(numRoutines) {
+				t.Errorf("unexpected routines: want <= %v, got %v", numRoutines, n)
+			}
+			if closeCount := atomic.LoadInt32(&CloseCount); closeCount != 0 {
+				t.Errorf("unexpected close count: want 0, got %v", closeCount)
+			}
+		}
+	})
+
+	t.Run("设置文件头", func(t *testing.T) {
+		for _, much := range []bool{false, true} {
+			partSize := int64(1024 * 1024)
+			data := MakeBytesWithSize(rand.Intn(1024) + 1)
+			if much {
+				data = MakeBytesWithSize(int(partSize)*3 + rand.Intn(1024) + 1)
+			}
+			fileId := "/ivfzhou_test_file"
+			expires := time.Now().Add(time.Hour).Truncate(time.Second)
+			wantHeader := map[string]string{
+				"Content-Type":        "text/plain",
+				"Content-Disposition": "attachment; filename=a.txt",
+				"Cache-Control":       "max-age=3600",
+				"Content-Encoding":    "gzip",
+				"Expires":             expires.UTC().Format(http.TimeFormat),
+				"X-Cos-Meta-Owner":    "ivfzhou",
+			}
+			var headerCount int32
+			atomic.StoreInt32(&CloseCount, 0)
+			fn := func(req *http.Request) (*http.Response, error) {
+				auth := req.Header.Get("Authorization")
+				if !CheckAuthorization(auth, req.URL.Path, req.Method, req.Header, req.URL.Query()) {
+					t.Errorf("unexpected auth: got %v", auth)
+				}
+				initiate := req.Method == http.MethodPost && req.URL.Query().Has("uploads")
+				if initiate || (req.Method == http.MethodPut && !much) {
+					atomic.AddInt32(&headerCount, 1)
+					for k, v := range wantHeader {
+						if got := req.Header.Get(k); got != v {
+							t.Errorf("unexpected header %v: want %v, got %v", k, v, got)
+						}
+					}
+				} else if req.Method == http.MethodPut && len(req.Header.Get("Content-Type")) > 0 {
+					t.Errorf("unexpected header Content-Type: want empty, got %v", req.Header.Get("Content-Type"))
+				}
+				if initiate {
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body: NewReader([]byte("<InitiateMultipartUploadResult><UploadId>upload id"+
+							"</UploadId></InitiateMultipartUploadResult>"), nil, nil, nil),
+					}, nil
+				}
+				if req.Method == http.MethodGet {
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       NewReader([]byte("<ListPartsResult></ListPartsResult>"), nil, nil, nil),
+					}, nil
+				}
+				if _, err := io.Copy(io.Discard, req.Body); err != nil {
+					t.Errorf("unexpected error: want nil, got %v", err)
+				}
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       NewReader(nil, nil, nil, nil),
+				}, nil
+			}
+			client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)),
+				cos.WithPartSize(partSize), cos.WithMultiThreshold(2))
+			err := client.Upload(context.Background(), fileId, data,
+				cos.WithContentType("text/plain"),
+				cos.WithContentDisposition("attachment; filename=a.txt"),
+				cos.WithCacheControl("max-age=3600"),
+				cos.WithContentEncoding("gzip"),
+				cos.WithExpires(expires),
+				cos.WithMetadata(map[string]string{"owner": "ivfzhou"}))
+			if err != nil {
+				t.Errorf("unexpected error: want nil, got %v", err)
+			}
+			if n := atomic.LoadInt32(&headerCount); n != 1 {
+				t.Errorf("unexpected header count: want 1, got %v", n)
+			}
+
+			// 调用参数不影响客户端后续的上传。
+			wantHeader = map[string]string{"Content-Type": ""}
+			atomic.StoreInt32(&headerCount, 0)
+			if err = client.Upload(context.Background(), fileId, data); err != nil {
+				t.Errorf("unexpected error: want nil, got %v", err)
+			}
+			if closeCount := atomic.LoadInt32(&CloseCount); closeCount != 0 {
+				t.Errorf("unexpected close count: want 0, got %v", closeCount)
+			}
+		}
+	})
+
+	t.Run("上传结果", func(t *testing.T) {
+		for _, much := range []bool{false, true} {
+			partSize := int64(1024 * 1024)
+			data := MakeBytesWithSize(rand.Intn(1024) + 1)
+			if much {
+				data = MakeBytesWithSize(int(partSize)*3 + rand.Intn(1024) + 1)
+			}
+			lock := sync.Mutex{}
+			parts := make(map[int]int)
+			table := crc64.MakeTable(crc64.ECMA)
+			fn := func(req *http.Request) (*http.Response, error) {
+				header := http.Header{}
+				header.Set("x-cos-request-id", "request id")
+				query := req.URL.Query()
+				switch {
+				case req.Method == http.MethodPost && query.Has("uploads"):
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body: NewReader([]byte("<InitiateMultipartUploadResult><UploadId>upload id"+
+							"</UploadId></InitiateMultipartUploadResult>"), nil, nil, nil),
+					}, nil
+				case req.Method == http.MethodGet:
+					body := "<ListPartsResult>"
+					lock.Lock()
+					for k, v := range parts {
+						body += fmt.Sprintf("<Part><PartNumber>%d</PartNumber><ETag>%d</ETag><Size>%d</Size></Part>",
+							k, k, v)
+					}
+					lock.Unlock()
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       NewReader([]byte(body+"</ListPartsResult>"), nil, nil, nil),
+					}, nil
+				case req.Method == http.MethodPost:
+					header.Set("x-cos-hash-crc64ecma", strconv.FormatUint(crc64.Checksum(data, table), 10))
+					header.Set("x-cos-version-id", "version id")
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Header:     header,
+						Body: NewReader([]byte("<CompleteMultipartUploadResult><ETag>\"multi etag\"</ETag>"+
+							"</CompleteMultipartUploadResult>"), nil, nil, nil),
+					}, nil
+				}
+				bs, _ := io.ReadAll(req.Body)
+				if num := query.Get("partNumber"); len(num) > 0 {
+					n, _ := strconv.Atoi(num)
+					lock.Lock()
+					parts[n] = len(bs)
+					lock.Unlock()
+				} else {
+					header.Set("Etag", "\"etag\"")
+					header.Set("x-cos-hash-crc64ecma", strconv.FormatUint(crc64.Checksum(bs, table), 10))
+					header.Set("x-cos-version-id", "version id")
+				}
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Header:     header,
+					Body:       NewReader(nil, nil, nil, nil),
+				}, nil
+			}
+			client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)),
+				cos.WithPartSize(partSize), cos.WithMultiThreshold(2))
+			result, err := client.UploadWithResult(context.Background(), "/ivfzhou_test_file", data)
+			if err != nil {
+				t.Fatalf("unexpected error: want nil, got %v", err)
+			}
+			want := cos.UploadResult{
+				EntityTag: `"etag"`,
+				Crc64:     strconv.FormatUint(crc64.Checksum(data, table), 10),
+				VersionId: "version id",
+				Size:      int64(len(data)),
+				PartCount: 1,
+				RequestId: "request id",
+			}
+			if much {
+				want.EntityTag, want.PartCount = `"multi etag"`, 4
+			}
+			if *result != want {
+				t.Errorf("unexpected result: want %+v, got %+v", want, *result)
+			}
+			if !much {
+				continue
+			}
+			result, err = client.UploadFromReaderWithResult(context.Background(), "/ivfzhou_test_file",
+				bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("unexpected error: want nil, got %v", err)
+			}
+			if *result != want {
+				t.Errorf("unexpected result: want %+v, got %+v", want, *result)
+			}
+		}
+	})
+
 	t.Run("上下文终止", func(t *testing.T) {
 		for range 25 {
 			uploadId := "expected upload id"
@@ -3370,3 +3625,200 @@ func TestUploadFromDisk(t *testing.T) {
 		}
 	})
 }
+
+func TestResumableUploadFromDisk(t *testing.T) {
+	t.Run("断点续传", func(t *testing.T) {
+		for range 5 {
+			partSize := int64(1024 * 1024)
+			data := MakeBytesWithSize(int(partSize)*(rand.Intn(4)+3) + rand.Intn(1024) + 1)
+			partCount := (int64(len(data)) + partSize - 1) / partSize
+			filePath := filepath.Join(t.TempDir(), "ivfzhou_test_file")
+			if err := os.WriteFile(filePath, data, 0600); err != nil {
+				t.Fatalf("unexpected error: want nil, got %v", err)
+			}
+			checkpointPath := filePath + ".cp"
+			uploadId := "expected upload id"
+			fileId := "/ivfzhou_test_file"
+			occurErrPartNum := rand.Int63n(partCount) + 1
+			failed := true
+			uploaded := sync.Map{}
+			var initCount, abortCount, completeCount int32
+			var received []byte
+			fn := func(req *http.Request) (*http.Response, error) {
+				switch req.Method {
+				case http.MethodPost:
+					if req.URL.Query().Has("uploads") {
+						atomic.AddInt32(&initCount, 1)
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body: NewReader([]byte("<InitiateMultipartUploadResult><UploadId>"+
+								uploadId+"</UploadId></InitiateMultipartUploadResult>"), nil, nil, nil),
+						}, nil
+					}
+					atomic.AddInt32(&completeCount, 1)
+					for i := int64(1); i <= partCount; i++ {
+						v, ok := uploaded.Load(i)
+						if !ok {
+							t.Errorf("unexpected complete: part %v not uploaded", i)
+							continue
+						}
+						received = append(received, v.([]byte)...)
+					}
+				case http.MethodPut:
+					num, err := strconv.ParseInt(req.URL.Query().Get("partNumber"), 10, 64)
+					if err != nil {
+						t.Errorf("unexpected error: want nil, got %v", err)
+					}
+					if failed && num == occurErrPartNum {
+						return &http.Response{
+							StatusCode: http.StatusInternalServerError,
+							Body:       NewReader(nil, nil, nil, nil),
+						}, nil
+					}
+					if !failed {
+						if _, ok := uploaded.Load(num); ok {
+							t.Errorf("unexpected upload: part %v already uploaded", num)
+						}
+					}
+					bs, err := io.ReadAll(req.Body)
+					if err != nil {
+						t.Errorf("unexpected error: want nil, got %v", err)
+					}
+					uploaded.Store(num, bs)
+					header := http.Header{}
+					header.Set("ETag", "etag_"+strconv.FormatInt(num, 10))
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Header:     header,
+						Body:       NewReader(nil, nil, nil, nil),
+					}, nil
+				case http.MethodGet:
+					type PartInfo struct {
+						PartNumber string
+						ETag       string
+						Size       string
+					}
+					var rspData struct {
+						XMLName             xml.Name   `xml:"ListPartsResult"`
+						ListPartResultParts []PartInfo `xml:"Part"`
+					}
+					uploaded.Range(func(key, value any) bool {
+						rspData.ListPartResultParts = append(rspData.ListPartResultParts, PartInfo{
+							PartNumber: strconv.FormatInt(key.(int64), 10),
+							ETag:       "etag_" + strconv.FormatInt(key.(int64), 10),
+							Size:       strconv.Itoa(len(value.([]byte))),
+						})
+						return true
+					})
+					bs, err := xml.Marshal(&rspData)
+					if err != nil {
+						t.Errorf("unexpected error: want nil, got %v", err)
+					}
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       NewReader(bs, nil, nil, nil),
+					}, nil
+				case http.MethodDelete:
+					atomic.AddInt32(&abortCount, 1)
+				}
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       NewReader(nil, nil, nil, nil),
+				}, nil
+			}
+			client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)),
+				cos.WithPartSize(partSize), cos.WithMultiThreshold(2))
+			err := client.ResumableUploadFromDisk(context.Background(), fileId, filePath, checkpointPath)
+			if err == nil {
+				t.Errorf("unexpected error: want not nil, got %v", err)
+			}
+			if _, err = os.Stat(checkpointPath); err != nil {
+				t.Errorf("unexpected error: want nil, got %v", err)
+			}
+			failed = false
+			result, err := client.ResumableUploadFromDiskWithResult(context.Background(), fileId, filePath,
+				checkpointPath)
+			if err != nil {
+				t.Fatalf("unexpected error: want nil, got %v", err)
+			}
+			if result.Size != int64(len(data)) || int64(result.PartCount) != partCount {
+				t.Errorf("unexpected result: want %v/%v, got %v/%v", len(data), partCount, result.Size,
+					result.PartCount)
+			}
+			if n := atomic.LoadInt32(&initCount); n != 1 {
+				t.Errorf("unexpected init count: want 1, got %v", n)
+			}
+			if n := atomic.LoadInt32(&completeCount); n != 1 {
+				t.Errorf("unexpected complete count: want 1, got %v", n)
+			}
+			if n := atomic.LoadInt32(&abortCount); n != 0 {
+				t.Errorf("unexpected abort count: want 0, got %v", n)
+			}
+			if !bytes.Equal(received, data) {
+				t.Errorf("unexpected result: want %v, got %v", len(data), len(received))
+			}
+			if _, err = os.Stat(checkpointPath); !os.IsNotExist(err) {
+				t.Errorf("unexpected error: want not exist, got %v", err)
+			}
+		}
+	})
+
+	t.Run("检查点不匹配", func(t *testing.T) {
+		partSize := int64(1024 * 1024)
+		filePath := filepath.Join(t.TempDir(), "ivfzhou_test_file")
+		if err := os.WriteFile(filePath, MakeBytesWithSize(int(partSize)*3), 0600); err != nil {
+			t.Fatalf("unexpected error: want nil, got %v", err)
+		}
+		checkpointPath := filePath + ".cp"
+		var initCount int32
+		failed := true
+		var aborted []string
+		lock := sync.Mutex{}
+		fn := func(req *http.Request) (*http.Response, error) {
+			query := req.URL.Query()
+			switch {
+			case req.Method == http.MethodPost && query.Has("uploads"):
+				n := atomic.AddInt32(&initCount, 1)
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body: NewReader([]byte(fmt.Sprintf("<InitiateMultipartUploadResult><UploadId>upload id %d"+
+						"</UploadId></InitiateMultipartUploadResult>", n)), nil, nil, nil),
+				}, nil
+			case req.Method == http.MethodPut && failed:
+				return &http.Response{StatusCode: http.StatusBadRequest, Body: NewReader(nil, nil, nil, nil)}, nil
+			case req.Method == http.MethodDelete:
+				lock.Lock()
+				aborted = append(aborted, query.Get("uploadId"))
+				lock.Unlock()
+			case req.Method == http.MethodGet:
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       NewReader([]byte("<ListPartsResult></ListPartsResult>"), nil, nil, nil),
+				}, nil
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: NewReader(nil, nil, nil, nil)}, nil
+		}
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)),
+			cos.WithPartSize(partSize), cos.WithMultiThreshold(2), cos.WithNonCheckCrc64())
+		err := client.ResumableUploadFromDisk(context.Background(), "/ivfzhou_test_file", filePath, checkpointPath)
+		if err == nil {
+			t.Fatalf("unexpected error: want not nil, got %v", err)
+		}
+
+		// 本地文件变化后，丢弃之前的分片上传。
+		failed = false
+		if err = os.WriteFile(filePath, MakeBytesWithSize(int(partSize)*4), 0600); err != nil {
+			t.Fatalf("unexpected error: want nil, got %v", err)
+		}
+		err = client.ResumableUploadFromDisk(context.Background(), "/ivfzhou_test_file", filePath, checkpointPath)
+		if err != nil {
+			t.Fatalf("unexpected error: want nil, got %v", err)
+		}
+		if !slices.Equal(aborted, []string{"upload id 1"}) {
+			t.Errorf("unexpected aborted: want [upload id 1], got %v", aborted)
+		}
+		if n := atomic.LoadInt32(&initCount); n != 2 {
+			t.Errorf("unexpected init count: want 2, got %v", n)
+		}
+	})
+}
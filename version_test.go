@@ -0,0 +1,171 @@
+/*
+ * Copyright (c) 2025 ivfzhou
+ * tencent-cos-object-api is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package cos_test
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	cos "gitee.com/ivfzhou/tencent-cos-object-api"
+)
+
+func TestVersion(t *testing.T) {
+	t.Run("读取指定版本", func(t *testing.T) {
+		fn := func(req *http.Request) (*http.Response, error) {
+			if v := req.URL.Query().Get("versionId"); v != "v1" {
+				t.Errorf("unexpected versionId: want v1, got %v", v)
+			}
+			header := http.Header{}
+			header.Set("Content-Length", "3")
+			header.Set("x-cos-version-id", "v1")
+			body := []byte("abc")
+			if req.Method == http.MethodHead {
+				body = nil
+			}
+			return &http.Response{StatusCode: http.StatusOK, Header: header, Body: NewReader(body, nil, nil, nil)}, nil
+		}
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)))
+		info, err := client.Info(context.Background(), "a.txt", cos.WithVersionId("v1"))
+		if err != nil || info.VersionId != "v1" {
+			t.Errorf("unexpected info: want v1, got %v, %v", info, err)
+		}
+		rc, _, err := client.Download(context.Background(), "a.txt", cos.WithVersionId("v1"))
+		if err != nil {
+			t.Fatalf("unexpected error: want nil, got %v", err)
+		}
+		data, err := io.ReadAll(rc)
+		_ = rc.Close()
+		if err != nil || string(data) != "abc" {
+			t.Errorf("unexpected data: want abc, got %s, %v", data, err)
+		}
+	})
+
+	t.Run("删除指定版本", func(t *testing.T) {
+		fn := func(req *http.Request) (*http.Response, error) {
+			if req.Method != http.MethodDelete || req.URL.Query().Get("versionId") != "v1" {
+				t.Errorf("unexpected request: got %v %v", req.Method, req.URL)
+			}
+			return &http.Response{StatusCode: http.StatusNoContent, Body: NewReader(nil, nil, nil, nil)}, nil
+		}
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)))
+		if err := client.Delete(context.Background(), "a.txt", cos.WithVersionId("v1")); err != nil {
+			t.Errorf("unexpected error: want nil, got %v", err)
+		}
+	})
+
+	t.Run("批量删除指定版本", func(t *testing.T) {
+		fn := func(req *http.Request) (*http.Response, error) {
+			var reqObj struct {
+				Object []struct{ Key, VersionId string }
+			}
+			bs, _ := io.ReadAll(req.Body)
+			if err := xml.Unmarshal(bs, &reqObj); err != nil || len(reqObj.Object) != 2 ||
+				reqObj.Object[0].VersionId != "v1" || reqObj.Object[1].VersionId != "" {
+				t.Errorf("unexpected request body: got %s", bs)
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: NewReader([]byte(`<DeleteResult>
+<Error><Key>a.txt</Key><VersionId>v1</VersionId><Code>AccessDenied</Code></Error>
+</DeleteResult>`), nil, nil, nil)}, nil
+		}
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)))
+		undeleted := client.DeleteVersions(context.Background(),
+			cos.FileVersionId{FileId: "/a.txt", VersionId: "v1"}, cos.FileVersionId{FileId: "b.txt"})
+		err := undeleted[cos.FileVersionId{FileId: "a.txt", VersionId: "v1"}]
+		if len(undeleted) != 1 || !errors.Is(err, cos.ErrAccessDenied) {
+			t.Errorf("unexpected undeleted: got %v", undeleted)
+		}
+	})
+
+	t.Run("上传返回版本", func(t *testing.T) {
+		fn := func(req *http.Request) (*http.Response, error) {
+			header := http.Header{}
+			header.Set("x-cos-version-id", "v2")
+			return &http.Response{StatusCode: http.StatusOK, Header: header, Body: NewReader(nil, nil, nil, nil)}, nil
+		}
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)), cos.WithNonCheckCrc64())
+		result, err := client.UploadWithResult(context.Background(), "a.txt", []byte("abc"))
+		if err != nil {
+			t.Fatalf("unexpected error: want nil, got %v", err)
+		}
+		if result.VersionId != "v2" {
+			t.Errorf("unexpected versionId: want v2, got %v", result.VersionId)
+		}
+	})
+
+	t.Run("复制指定版本", func(t *testing.T) {
+		fn := func(req *http.Request) (*http.Response, error) {
+			if req.Method == http.MethodHead {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Header:     http.Header{"Content-Length": []string{"3"}},
+					Body:       NewReader(nil, nil, nil, nil),
+				}, nil
+			}
+			if source := req.Header.Get("x-cos-copy-source"); !strings.HasSuffix(source, "/a.txt?versionId=v1") {
+				t.Errorf("unexpected copy source: got %v", source)
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       NewReader([]byte("<CopyObjectResult></CopyObjectResult>"), nil, nil, nil),
+			}, nil
+		}
+		client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)))
+		if err := client.Copy(context.Background(), "a.txt", "b.txt", cos.WithVersionId("v1")); err != nil {
+			t.Errorf("unexpected error: want nil, got %v", err)
+		}
+	})
+}
+
+func TestListFileVersions(t *testing.T) {
+	fn := func(req *http.Request) (*http.Response, error) {
+		query := req.URL.Query()
+		if !query.Has("versions") || query.Get("prefix") != "dir/" || query.Get("key-marker") != "dir/0" ||
+			query.Get("version-id-marker") != "v0" {
+			t.Errorf("unexpected query: got %v", query)
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: NewReader([]byte(`<ListVersionsResult>
+<IsTruncated>true</IsTruncated><NextKeyMarker>dir/b</NextKeyMarker><NextVersionIdMarker>v4</NextVersionIdMarker>
+<Version><Key>dir/a</Key><VersionId>v2</VersionId><IsLatest>false</IsLatest>
+<LastModified>2025-01-02T03:04:05.000Z</LastModified><ETag>"e2"</ETag><Size>2</Size><StorageClass>STANDARD</StorageClass></Version>
+<DeleteMarker><Key>dir/a</Key><VersionId>v3</VersionId><IsLatest>true</IsLatest>
+<LastModified>2025-01-03T03:04:05.000Z</LastModified><Owner><ID>1</ID><DisplayName>ivfzhou</DisplayName></Owner></DeleteMarker>
+<Version><Key>dir/b</Key><VersionId>v4</VersionId><IsLatest>true</IsLatest><Size>4</Size></Version>
+</ListVersionsResult>`), nil, nil, nil)}, nil
+	}
+	client := cos.NewClient(host, appKey, appSecret, cos.WithHttpClient(MockHttpClient(fn)))
+	res, err := client.ListFileVersions(context.Background(), "/dir/", "", "dir/0", "v0", 3)
+	if err != nil {
+		t.Fatalf("unexpected error: want nil, got %v", err)
+	}
+	if len(res.Versions) != 3 {
+		t.Fatalf("unexpected versions: want 3, got %v", len(res.Versions))
+	}
+	if v := res.Versions[0]; v.ID != "dir/a" || v.VersionId != "v2" || v.IsLatest || v.IsDeleteMarker || v.Size != 2 ||
+		v.StorageClass != cos.StorageClassStandard ||
+		!v.UploadTime.Equal(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("unexpected version: got %+v", v)
+	}
+	if v := res.Versions[1]; v.VersionId != "v3" || !v.IsLatest || !v.IsDeleteMarker || v.Owner.DisplayName != "ivfzhou" ||
+		v.StorageClass != "" {
+		t.Errorf("unexpected delete marker: got %+v", v)
+	}
+	if res.Versions[2].VersionId != "v4" || res.NextKeyOffset != "dir/b" || res.NextVersionIdOffset != "v4" {
+		t.Errorf("unexpected result: got %+v", res)
+	}
+}